@@ -8,100 +8,146 @@ import (
 
 // Вынести основные проверки структурных тегов на уровень выше, добавить проверку пустых значений
 
-func adaptChoice(set tagValue, value reflect.Value) (err error) {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return nil // Для nil указателей choice не применяется
+// compileChoice splits and parses set of rst-choice once for values of type t.
+// Zero values are not checked.
+func compileChoice(set tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
+	rule, err := choiceRule(strings.Split(string(set), SET_DELIMITER), t)
+
+	return func(value reflect.Value) error {
+		if value.IsZero() {
+			return nil
 		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
-	}
+		return rule(value)
+	}, err
+}
 
-	if value.IsZero() {
-		return nil
+func choiceRule(options []string, t reflect.Type) (valueRule, error) {
+	switch t {
+	case durationType:
+		return adaptChoiceDuration(options)
+	case timeType:
+		return adaptChoiceTime(options)
 	}
 
-	options := strings.Split(string(set), SET_DELIMITER)
-
-	switch value.Kind() {
+	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		err = adaptChoiceInt(options, value)
+		return adaptChoiceInt(options)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		err = adaptChoiceUint(options, value)
+		return adaptChoiceUint(options)
 
 	case reflect.Float64, reflect.Float32:
-		err = adaptChoiceFloat(options, value)
+		return adaptChoiceFloat(options)
 
 	case reflect.String:
-		err = adaptChoiceString(options, value)
+		return adaptChoiceString(options)
+
+	case reflect.Bool:
+		return adaptChoiceBool(options)
 
 	default:
-		err = ErrInvalidTags
+		return failRule(ErrInvalidTags)
 
 	}
-	return
 }
 
-func adaptChoiceInt(set []string, value reflect.Value) error {
-	for _, option := range set {
+func adaptChoiceInt(set []string) (valueRule, error) {
+	values := make([]int64, len(set))
+	for i, option := range set {
 		val, err := strconv.Atoi(option)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = int64(val)
+	}
 
-		if int64(val) == value.Int() {
-			return nil
+	return func(value reflect.Value) error {
+		for _, val := range values {
+			if val == value.Int() {
+				return nil
+			}
 		}
-	}
 
-	defVal, _ := strconv.Atoi(set[0])
-	value.SetInt(int64(defVal))
-	return nil
+		value.SetInt(values[0])
+		return nil
+	}, nil
 }
 
-func adaptChoiceFloat(set []string, value reflect.Value) error {
-	for _, option := range set {
+func adaptChoiceFloat(set []string) (valueRule, error) {
+	values := make([]float64, len(set))
+	for i, option := range set {
 		val, err := strconv.ParseFloat(option, 64)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = val
+	}
 
-		if val == value.Float() {
-			return nil
+	return func(value reflect.Value) error {
+		for _, val := range values {
+			if val == value.Float() {
+				return nil
+			}
 		}
-	}
 
-	defVal, _ := strconv.ParseFloat(set[0], 64)
-	value.SetFloat(defVal)
-	return nil
+		value.SetFloat(values[0])
+		return nil
+	}, nil
 }
 
-func adaptChoiceUint(set []string, value reflect.Value) error {
-	for _, option := range set {
+func adaptChoiceUint(set []string) (valueRule, error) {
+	values := make([]uint64, len(set))
+	for i, option := range set {
 		val, err := strconv.ParseUint(option, 10, 64)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = val
+	}
 
-		if val == value.Uint() {
-			return nil
+	return func(value reflect.Value) error {
+		for _, val := range values {
+			if val == value.Uint() {
+				return nil
+			}
 		}
-	}
 
-	defVal, _ := strconv.ParseUint(set[0], 10, 64)
-	value.SetUint(defVal)
-	return nil
+		value.SetUint(values[0])
+		return nil
+	}, nil
 }
 
-func adaptChoiceString(set []string, value reflect.Value) error {
-	for _, option := range set {
-		if option == value.String() {
-			return nil
+func adaptChoiceString(set []string) (valueRule, error) {
+	return func(value reflect.Value) error {
+		for _, option := range set {
+			if option == value.String() {
+				return nil
+			}
+		}
+
+		value.SetString(set[0])
+		return nil
+	}, nil
+}
+
+func adaptChoiceBool(set []string) (valueRule, error) {
+	values := make([]bool, len(set))
+	for i, option := range set {
+		val, err := strconv.ParseBool(option)
+		if err != nil {
+			return failRule(err)
 		}
+		values[i] = val
 	}
 
-	value.SetString(set[0])
-	return nil
+	return func(value reflect.Value) error {
+		for _, val := range values {
+			if val == value.Bool() {
+				return nil
+			}
+		}
+
+		value.SetBool(values[0])
+		return nil
+	}, nil
 }
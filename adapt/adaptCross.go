@@ -0,0 +1,567 @@
+package adapt
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// crossRule is a rule depending on the value of another field,
+// referenced by its dotted path after REF_PREFIX, e.g. rst-max:"@pool.max"
+// or rst-required-if:"@tls.enabled=true".
+type crossRule struct {
+	name    tagName
+	value   tagValue
+	ref     string // dotted path of the referenced field
+	cond    string // value the referenced field must be equal to
+	hasCond bool   // without condition the referenced field must be non-zero
+	arg     string // value set by rst-default-if
+
+	// fallback is rst-default of the field, which rst-default-if
+	// sets when the condition does not hold
+	fallback    tagValue
+	hasFallback bool
+
+	err error // error of parsing the tag, reported when applied
+}
+
+// isCrossRule reports whether the tag references another field.
+func isCrossRule(name tagName, tv tagValue) bool {
+	switch name {
+	case RST_DEFAULT_IF, RST_REQUIRED_IF:
+		return true
+	case RST_MIN, RST_MAX:
+		return strings.HasPrefix(string(tv), REF_PREFIX)
+	default:
+		return false
+	}
+}
+
+// splitCrossTags separates rules referencing other fields from the
+// rest of the tags. Cross rules are returned in order of application.
+func splitCrossTags(tags tagsList) (tagsList, []crossRule) {
+	var cross []crossRule
+	for _, tn := range crossOrder {
+		if tv, ok := tags[tn]; ok && isCrossRule(tn, tv) {
+			rule := parseCrossRule(tn, tv)
+			if tn == RST_DEFAULT_IF {
+				rule.fallback, rule.hasFallback = tags[RST_DEFAULT]
+			}
+			cross = append(cross, rule)
+		}
+	}
+	if len(cross) == 0 {
+		return tags, nil
+	}
+
+	simple := make(tagsList, len(tags)-len(cross))
+	for tn, tv := range tags {
+		if !isCrossRule(tn, tv) {
+			simple[tn] = tv
+		}
+	}
+	return simple, cross
+}
+
+// parseCrossRule parses "@path" of rst-min and rst-max,
+// "@path[=value]" of rst-required-if and "@path[=value]**default"
+// of rst-default-if.
+func parseCrossRule(name tagName, tv tagValue) crossRule {
+	rule := crossRule{name: name, value: tv}
+
+	ref := string(tv)
+	if name == RST_DEFAULT_IF {
+		var found bool
+		if ref, rule.arg, found = strings.Cut(ref, VAL_DELIMITER); !found {
+			rule.err = fmt.Errorf("no default: %w", ErrInvalidTags)
+			return rule
+		}
+	}
+
+	ref, ok := strings.CutPrefix(ref, REF_PREFIX)
+	if !ok {
+		rule.err = fmt.Errorf("no reference %q: %w", REF_PREFIX, ErrInvalidTags)
+		return rule
+	}
+	if name == RST_DEFAULT_IF || name == RST_REQUIRED_IF {
+		ref, rule.cond, rule.hasCond = strings.Cut(ref, "=")
+	}
+	if ref == "" {
+		rule.err = fmt.Errorf("empty reference: %w", ErrInvalidTags)
+		return rule
+	}
+
+	rule.ref = ref
+	return rule
+}
+
+// apply applies the rule to value of the field, ref is the
+// dereferenced value of the referenced field.
+func (rule crossRule) apply(value, ref reflect.Value) error {
+	switch rule.name {
+	case RST_MIN, RST_MAX:
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return nil
+			}
+			value = value.Elem()
+		}
+		return adaptBound(rule.name, value, ref)
+
+	case RST_DEFAULT_IF:
+		return rule.adaptDefaultIf(value, ref)
+
+	default:
+		holds, err := rule.holds(ref)
+		if err != nil {
+			return err
+		}
+		if holds && value.IsZero() {
+			return ErrRequired
+		}
+		return nil
+
+	}
+}
+
+// adaptBound sets value to bound if it is less than bound (rst-min)
+// or greater than bound (rst-max). Numeric bounds the type of the value
+// cannot hold exactly, such as fractions for integers, are errors.
+func adaptBound(name tagName, value, bound reflect.Value) error {
+	if orderedClass(value.Type()) == 0 || orderedClass(value.Type()) != orderedClass(bound.Type()) {
+		return fmt.Errorf("cannot compare %s with %s: %w", value.Type(), bound.Type(), ErrInvalidTags)
+	}
+	if isNumberKind(bound.Kind()) {
+		converted, err := convertNumber(bound, value.Type())
+		if err != nil {
+			return err
+		}
+		bound = converted
+	} else {
+		bound = bound.Convert(value.Type())
+	}
+
+	c := compareValues(value, bound)
+	if (name == RST_MIN && c < 0) || (name == RST_MAX && c > 0) {
+		value.Set(bound)
+	}
+	return nil
+}
+
+func (rule crossRule) adaptDefaultIf(value, ref reflect.Value) error {
+	defVal := reflect.New(value.Type()).Elem()
+	if value.Kind() == reflect.Ptr {
+		defVal.Set(reflect.New(value.Type().Elem()))
+		defVal = defVal.Elem()
+	}
+	holds, err := rule.holds(ref)
+	if err != nil {
+		return err
+	}
+
+	raw := tagValue(rule.arg)
+	if !holds {
+		raw = rule.fallback
+	}
+	if err := setValue(raw, defVal); err != nil {
+		return err
+	}
+	if (!holds && !rule.hasFallback) || !value.IsZero() {
+		return nil
+	}
+
+	if value.Kind() == reflect.Ptr {
+		value.Set(defVal.Addr())
+	} else {
+		value.Set(defVal)
+	}
+	return nil
+}
+
+// hasDefaultIf reports whether rst-default of the field is applied
+// by rst-default-if when its condition does not hold.
+func (fr *fieldRules) hasDefaultIf() bool {
+	for _, rule := range fr.cross {
+		if rule.name == RST_DEFAULT_IF {
+			return true
+		}
+	}
+	return false
+}
+
+// holds reports whether condition of rst-default-if or rst-required-if
+// holds for the referenced value.
+func (rule crossRule) holds(ref reflect.Value) (bool, error) {
+	if !rule.hasCond {
+		return !ref.IsZero(), nil
+	}
+
+	condValue := reflect.New(ref.Type()).Elem()
+	if err := setValue(tagValue(rule.cond), condValue); err != nil {
+		return false, err
+	}
+	if ref.Type() == timeType {
+		return ref.Interface().(time.Time).Equal(condValue.Interface().(time.Time)), nil
+	}
+	return ref.Equal(condValue), nil
+}
+
+// orderedClass groups types which values can be compared with each other,
+// it returns 0 for types without order.
+func orderedClass(t reflect.Type) int {
+	if t == timeType {
+		return 3
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 1
+	case reflect.String:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// compareValues compares values of the same ordered type.
+func compareValues(x, y reflect.Value) int {
+	if x.Type() == timeType {
+		return x.Interface().(time.Time).Compare(y.Interface().(time.Time))
+	}
+	switch x.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(x.Int(), y.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(x.Uint(), y.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(x.Float(), y.Float())
+	default:
+		return cmp.Compare(x.String(), y.String())
+	}
+}
+
+// fieldRef is a field of the adapted structure found by its path.
+type fieldRef struct {
+	value reflect.Value
+	rules *fieldRules
+	scope []elemScope // elements of slices, arrays and maps containing the field
+}
+
+// elemScope identifies an element of the slice, array or map with the path.
+type elemScope struct {
+	path string
+	id   int
+}
+
+// crossState holds fields of the adapted structure collected
+// for evaluation of rules referencing other fields.
+type crossState struct {
+	refs   map[string][]fieldRef
+	paths  []string // paths of fields with cross rules in order of traversal
+	cross  map[string]bool
+	absent []string // paths of nil pointers and empty containers
+	elems  int      // number of elements of containers seen
+
+	// flush writes copies of values stored in maps
+	// and interfaces back after the rules are applied.
+	flush []func()
+}
+
+// adaptCross applies rules referencing other fields after the rest
+// of the rules, so referenced fields already have their final values.
+// Fields are processed in topological order of references,
+// fields referencing each other in a cycle are reported with ErrRuleCycle.
+func (a *Adapter) adaptCross(root reflect.Value, st *adaptState) error {
+	if !st.cross {
+		return nil
+	}
+
+	cs := &crossState{refs: make(map[string][]fieldRef), cross: make(map[string]bool)}
+	a.collectFields(root, "", nil, cs)
+	if st.dryRun {
+		cs.useAdapted(st.adapted)
+	}
+
+	order, errs := cs.order()
+	for _, err := range errs {
+		if err := st.fail(err); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range order {
+		for i := range cs.refs[path] {
+			if cs.refs[path][i].rules == nil {
+				continue
+			}
+			if err := a.adaptCrossField(path, &cs.refs[path][i], cs, st); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !st.dryRun {
+		for _, flush := range cs.flush {
+			flush()
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) adaptCrossField(path string, field *fieldRef, cs *crossState, st *adaptState) error {
+	value := field.value
+	if st.dryRun {
+		// Input must stay untouched, the copy is used by dependent fields
+		value = detachedCopy(value)
+		field.value = value
+	}
+
+	for _, rule := range field.rules.cross {
+		ref, found, err := cs.resolve(rule.ref, field.scope)
+		if err == nil {
+			err = rule.err
+		}
+		if err == nil && !found {
+			continue
+		}
+
+		apply := func(value reflect.Value) error {
+			if err != nil {
+				return err
+			}
+			return rule.apply(value, ref)
+		}
+		if err := a.applyRule(rule.name, rule.value, value, path, st, apply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectFields records fields of the value by their dotted paths
+// together with elements of containers they are in.
+func (a *Adapter) collectFields(value reflect.Value, path string, scope []elemScope, cs *crossState) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			cs.absent = append(cs.absent, path)
+			return
+		}
+
+		elem := value.Elem()
+		if value.Kind() == reflect.Interface && elem.Kind() != reflect.Ptr {
+			// Значение в интерфейсе не адресуемо: работаем с копией и записываем ее обратно
+			copyElem := reflect.New(elem.Type()).Elem()
+			copyElem.Set(elem)
+			a.collectFields(copyElem, path, scope, cs)
+			if value.CanSet() {
+				cs.flush = append(cs.flush, func() { value.Set(copyElem) })
+			}
+			return
+		}
+		a.collectFields(elem, path, scope, cs)
+
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return
+		}
+
+		for _, field := range a.planFor(value.Type()).fields {
+			fieldValue := value.Field(field.index)
+			if !fieldValue.CanInterface() {
+				continue
+			}
+
+			fieldPath := joinPath(path, field.name)
+			cs.refs[fieldPath] = append(cs.refs[fieldPath], fieldRef{value: fieldValue, rules: field.rules, scope: scope})
+			if field.rules != nil && len(field.rules.cross) > 0 && !cs.cross[fieldPath] {
+				cs.cross[fieldPath] = true
+				cs.paths = append(cs.paths, fieldPath)
+			}
+			a.collectFields(fieldValue, fieldPath, scope, cs)
+		}
+
+	case reflect.Array, reflect.Slice:
+		if value.Len() == 0 {
+			cs.absent = append(cs.absent, path)
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			a.collectFields(value.Index(i), path, cs.enter(scope, path), cs)
+		}
+
+	case reflect.Map:
+		if value.Len() == 0 {
+			cs.absent = append(cs.absent, path)
+			return
+		}
+		if isSimpleType(reflect.New(value.Type().Elem()).Elem()) {
+			return
+		}
+
+		for _, key := range value.MapKeys() {
+			// Значения карты не адресуемы: работаем с копией и записываем ее обратно
+			valCopy := reflect.New(value.Type().Elem()).Elem()
+			valCopy.Set(value.MapIndex(key))
+			a.collectFields(valCopy, path, cs.enter(scope, path), cs)
+			cs.flush = append(cs.flush, func() { value.SetMapIndex(key, valCopy) })
+		}
+
+	}
+}
+
+// useAdapted replaces values of fields with their copies adapted
+// by the rest of the rules in dry run.
+func (cs *crossState) useAdapted(adapted map[string][]reflect.Value) {
+	for path, values := range adapted {
+		refs := cs.refs[path]
+		if len(values) != 1 || len(refs) != 1 {
+			continue
+		}
+
+		value, field := values[0], refs[0].value
+		switch {
+		case value.Type() == field.Type():
+			refs[0].value = value
+
+		case field.Kind() == reflect.Ptr && value.Type() == field.Type().Elem():
+			ptr := reflect.New(value.Type())
+			ptr.Elem().Set(value)
+			refs[0].value = ptr
+
+		}
+	}
+}
+
+// enter returns scope of a new element of the container with the path.
+func (cs *crossState) enter(scope []elemScope, path string) []elemScope {
+	cs.elems++
+	return append(slices.Clip(scope), elemScope{path: path, id: cs.elems})
+}
+
+// resolve returns dereferenced value of the field with the path,
+// referenced from a field in the scope. Fields in elements of the
+// containers which contain the referencing field are taken from the
+// same elements. Fields behind nil pointers and in empty containers
+// are not found.
+func (cs *crossState) resolve(path string, scope []elemScope) (reflect.Value, bool, error) {
+	refs := cs.refs[path]
+	if len(refs) > 1 && len(scope) > 0 {
+		refs = slices.DeleteFunc(slices.Clone(refs), func(ref fieldRef) bool {
+			return !sameElements(ref.scope, scope)
+		})
+	}
+	switch len(refs) {
+	case 0:
+		for _, absent := range cs.absent {
+			if path == absent || strings.HasPrefix(path, absent+".") {
+				return reflect.Value{}, false, nil
+			}
+		}
+		return reflect.Value{}, false, fmt.Errorf("unknown field %s: %w", path, ErrInvalidTags)
+
+	case 1:
+		value := refs[0].value
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return reflect.Value{}, false, nil
+			}
+			value = value.Elem()
+		}
+		return value, true, nil
+
+	default:
+		return reflect.Value{}, false, fmt.Errorf("ambiguous field %s: %w", path, ErrInvalidTags)
+
+	}
+}
+
+// sameElements reports whether elements of containers present
+// in both scopes are the same.
+func sameElements(x, y []elemScope) bool {
+	for _, sx := range x {
+		for _, sy := range y {
+			if sx.path == sy.path && sx.id != sy.id {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// order returns paths of fields with cross rules sorted so that
+// referenced fields go before fields referencing them.
+// Fields in cycles and fields depending on them are left out.
+func (cs *crossState) order() ([]string, []*FieldError) {
+	const (
+		visiting = iota + 1
+		done
+		failed
+	)
+
+	var (
+		order []string
+		errs  []*FieldError
+		stack []string
+		state = make(map[string]int)
+	)
+
+	var visit func(path string) bool
+	visit = func(path string) bool {
+		switch state[path] {
+		case done:
+			return true
+		case failed:
+			return false
+		case visiting:
+			cycle := append(slices.Clone(stack[slices.Index(stack, path):]), path)
+			errs = append(errs, &FieldError{
+				Path: path,
+				Err:  fmt.Errorf("%w: %s", ErrRuleCycle, strings.Join(cycle, " -> ")),
+			})
+			return false
+		}
+
+		state[path] = visiting
+		stack = append(stack, path)
+		ok := true
+		for _, ref := range cs.deps(path) {
+			if !visit(ref) {
+				ok = false
+			}
+		}
+		stack = stack[:len(stack)-1]
+
+		state[path] = failed
+		if ok {
+			state[path] = done
+			order = append(order, path)
+		}
+		return ok
+	}
+
+	for _, path := range cs.paths {
+		visit(path)
+	}
+	return order, errs
+}
+
+// deps returns referenced fields which have cross rules themselves.
+func (cs *crossState) deps(path string) []string {
+	var deps []string
+	for _, field := range cs.refs[path] {
+		if field.rules == nil {
+			continue
+		}
+		for _, rule := range field.rules.cross {
+			if cs.cross[rule.ref] && !slices.Contains(deps, rule.ref) {
+				deps = append(deps, rule.ref)
+			}
+		}
+	}
+	return deps
+}
@@ -5,40 +5,51 @@ import (
 	"strconv"
 )
 
-// Добавить проверку на соответсвие дефолта и рестрикта: дефолтное значение должно входить в интервал
-
-func adaptDefault(defaultValue tagValue, value reflect.Value) (err error) {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			// Создаем новый указатель с дефолтным значением
-			newValue := reflect.New(value.Type().Elem())
-			if err := adaptDefault(defaultValue, newValue.Elem()); err != nil {
-				return err
-			}
-			value.Set(newValue)
-			return nil
-		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
+// compileDefault parses value of rst-default once for values of type t.
+// Default is set only to zero values. Whether default satisfies
+// other rules of the field is checked by CheckType.
+func compileDefault(defaultValue tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
+	if t == timeType {
+		return adaptDefaultTime(defaultValue)
 	}
 
-	if !value.IsZero() {
+	defVal := reflect.New(t).Elem()
+	err := setValue(defaultValue, defVal)
+
+	return func(value reflect.Value) error {
+		if !value.IsZero() {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value.Set(defVal)
 		return nil
+	}, err
+}
+
+// setValue parses string representation of the value according
+// to the kind of the field and sets the result.
+func setValue(rawValue tagValue, value reflect.Value) (err error) {
+	if isTimeType(value.Type()) {
+		return setTimeValue(rawValue, value)
 	}
 
 	switch value.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		err = adaptDefaultInt(defaultValue, value)
+		err = adaptDefaultInt(rawValue, value)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		err = adaptDefaultUint(defaultValue, value)
+		err = adaptDefaultUint(rawValue, value)
 
 	case reflect.Float64, reflect.Float32:
-		err = adaptDefaultFloat(defaultValue, value)
+		err = adaptDefaultFloat(rawValue, value)
 
 	case reflect.String:
-		err = adaptDefaultString(defaultValue, value)
+		err = adaptDefaultString(rawValue, value)
+
+	case reflect.Bool:
+		err = adaptDefaultBool(rawValue, value)
 
 	default:
 		err = ErrInvalidTags
@@ -81,3 +92,13 @@ func adaptDefaultString(defaultValue tagValue, value reflect.Value) error {
 	value.SetString(string(defaultValue))
 	return nil
 }
+
+func adaptDefaultBool(defaultValue tagValue, value reflect.Value) error {
+	defVal, err := strconv.ParseBool(string(defaultValue))
+	if err != nil {
+		return err
+	}
+
+	value.SetBool(defVal)
+	return nil
+}
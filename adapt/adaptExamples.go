@@ -2,8 +2,6 @@ package adapt
 
 import (
 	"fmt"
-	"log"
-	"os"
 )
 
 // RunAdaptExamples демонстрирует работу основного функционала AdaptStruct
@@ -47,7 +45,7 @@ func runSimpleAdaptExample() {
 
 	fmt.Printf("До адаптации: %+v\n", original)
 
-	var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	a := New()
 
 	adapted, err := a.AdaptStruct(original)
 	if err != nil {
@@ -76,7 +74,7 @@ func runChoiceAdaptExample() {
 
 	fmt.Printf("До адаптации: %+v\n", original)
 
-	var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	a := New()
 
 	adapted, err := a.AdaptStruct(original)
 	if err != nil {
@@ -105,7 +103,7 @@ func runForbiddenAdaptExample() {
 
 	fmt.Printf("До адаптации: %+v\n", original)
 
-	var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	a := New()
 
 	adapted, err := a.AdaptStruct(original)
 	if err != nil {
@@ -137,7 +135,7 @@ func runSliceAdaptExample() {
 	fmt.Printf("  Names: %v (длина: %d)\n", original.Names, len(original.Names))
 	fmt.Printf("  Prices: %v (длина: %d)\n", original.Prices, len(original.Prices))
 
-	var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	a := New()
 
 	adapted, err := a.AdaptStruct(original)
 	if err != nil {
@@ -159,13 +157,13 @@ func runNestedAdaptExample() {
 	type Address struct {
 		Street  string `rst-default:"Unknown Street"`
 		City    string `rst-default:"Unknown City"`
-		ZipCode string `rst-regex:"[0-9]{5}"`
+		ZipCode string `rst-regex:"match:^[0-9]{5}$**00000"`
 	}
 
 	type User struct {
-		Name    string   `rst-regex:"[a-zA-Z]+" rst-default:"Unknown"`
+		Name    string   `rst-regex:"keep:[a-zA-Z]+" rst-default:"Unknown"`
 		Age     int      `rst-min:"18" rst-max:"120" rst-default:"25"`
-		Email   string   `rst-regex:"^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$"`
+		Email   string   `rst-regex:"match:^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$**"`
 		Address Address  `info:"Адрес пользователя"`
 		Tags    []string `rst-max:"5"`
 	}
@@ -189,7 +187,7 @@ func runNestedAdaptExample() {
 	fmt.Printf("  Address: %+v\n", original.Address)
 	fmt.Printf("  Tags: %v (длина: %d)\n", original.Tags, len(original.Tags))
 
-	var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	a := New()
 
 	adapted, err := a.AdaptStruct(original)
 	if err != nil {
@@ -6,144 +6,153 @@ import (
 	"strings"
 )
 
-func adaptForbidden(forbiddenValue tagValue, value reflect.Value) (err error) {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return nil // Для nil указателей forbidden не применяется
-		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
-	}
-
+// compileForbidden parses list of rst-forbidden values and their
+// replacement once for values of type t.
+func compileForbidden(forbiddenValue tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
 	options := strings.Split(string(forbiddenValue), SET_DELIMITER)
 	withDef := strings.Split(options[len(options)-1], VAL_DELIMITER)
 	if len(withDef) != 2 {
-		return ErrInvalidTags
+		return failRule(ErrInvalidTags)
 	}
 	options = options[:len(options)-1]
 	options = append(options, withDef...)
 
-	switch value.Kind() {
+	switch t {
+	case durationType:
+		return adaptForbiddenDuration(options)
+	case timeType:
+		return adaptForbiddenTime(options)
+	}
+
+	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		err = adaptForbiddenInt(options, value)
+		return adaptForbiddenInt(options)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		err = adaptForbiddenUint(options, value)
+		return adaptForbiddenUint(options)
 
 	case reflect.Float32:
-		err = adaptForbiddenFloat32(options, value)
+		return adaptForbiddenFloat(options, 32)
 
 	case reflect.Float64:
-		err = adaptForbiddenFloat64(options, value)
+		return adaptForbiddenFloat(options, 64)
 
 	case reflect.String:
-		err = adaptForbiddenString(options, value)
+		return adaptForbiddenString(options)
+
+	case reflect.Bool:
+		return adaptForbiddenBool(options)
 
 	default:
-		err = ErrInvalidTags
+		return failRule(ErrInvalidTags)
 
 	}
-	return
 }
 
-func adaptForbiddenInt(forbiddenValue []string, value reflect.Value) error {
-	lenght := len(forbiddenValue)
-	for i := 0; i < lenght-1; i++ {
-
-		forVal, err := strconv.Atoi(forbiddenValue[i])
+func adaptForbiddenInt(forbiddenValue []string) (valueRule, error) {
+	values := make([]int64, len(forbiddenValue))
+	for i, option := range forbiddenValue {
+		val, err := strconv.Atoi(option)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = int64(val)
+	}
+	lenght := len(values)
 
-		if int64(forVal) == value.Int() {
-			defVal, err := strconv.Atoi(forbiddenValue[lenght-1])
-			if err != nil {
-				return err
+	return func(value reflect.Value) error {
+		for i := 0; i < lenght-1; i++ {
+			if values[i] == value.Int() {
+				value.SetInt(values[lenght-1])
+				return nil
 			}
-			value.SetInt(int64(defVal))
-			return nil
 		}
-	}
-
-	return nil
+		return nil
+	}, nil
 }
 
-func adaptForbiddenFloat64(forbiddenValue []string, value reflect.Value) error {
-	lenght := len(forbiddenValue)
-	for i := 0; i < lenght-1; i++ {
-
-		forVal, err := strconv.ParseFloat(forbiddenValue[i], 64)
+// adaptForbiddenFloat compares values with the precision of the field,
+// so float32 fields match values written with float32 precision.
+func adaptForbiddenFloat(forbiddenValue []string, bitSize int) (valueRule, error) {
+	values := make([]float64, len(forbiddenValue))
+	for i, option := range forbiddenValue {
+		val, err := strconv.ParseFloat(option, bitSize)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = val
+	}
+	lenght := len(values)
 
-		if forVal == value.Float() {
-			defVal, err := strconv.ParseFloat(forbiddenValue[lenght-1], 64)
-			if err != nil {
-				return err
+	return func(value reflect.Value) error {
+		for i := 0; i < lenght-1; i++ {
+			match := values[i] == value.Float()
+			if bitSize == 32 {
+				match = float32(values[i]) == float32(value.Float())
+			}
+			if match {
+				value.SetFloat(values[lenght-1])
+				return nil
 			}
-			value.SetFloat(defVal)
-			return nil
 		}
-	}
-
-	return nil
+		return nil
+	}, nil
 }
 
-func adaptForbiddenFloat32(forbiddenValue []string, value reflect.Value) error {
-	lenght := len(forbiddenValue)
-	for i := 0; i < lenght-1; i++ {
-
-		forVal, err := strconv.ParseFloat(forbiddenValue[i], 32)
+func adaptForbiddenUint(forbiddenValue []string) (valueRule, error) {
+	values := make([]uint64, len(forbiddenValue))
+	for i, option := range forbiddenValue {
+		val, err := strconv.ParseUint(option, 10, 64)
 		if err != nil {
-			return err
+			return failRule(err)
 		}
+		values[i] = val
+	}
+	lenght := len(values)
 
-		if float32(forVal) == float32(value.Float()) {
-			defVal, err := strconv.ParseFloat(forbiddenValue[lenght-1], 32)
-			if err != nil {
-				return err
+	return func(value reflect.Value) error {
+		for i := 0; i < lenght-1; i++ {
+			if values[i] == value.Uint() {
+				value.SetUint(values[lenght-1])
+				return nil
 			}
-			value.SetFloat(defVal)
-			return nil
 		}
-	}
-
-	return nil
+		return nil
+	}, nil
 }
 
-func adaptForbiddenUint(forbiddenValue []string, value reflect.Value) error {
+func adaptForbiddenString(forbiddenValue []string) (valueRule, error) {
 	lenght := len(forbiddenValue)
-	for i := 0; i < lenght-1; i++ {
-
-		forVal, err := strconv.ParseUint(forbiddenValue[i], 10, 64)
-		if err != nil {
-			return err
-		}
 
-		if forVal == value.Uint() {
-			defVal, err := strconv.ParseUint(forbiddenValue[lenght-1], 10, 64)
-			if err != nil {
-				return err
+	return func(value reflect.Value) error {
+		for i := 0; i < lenght-1; i++ {
+			if forbiddenValue[i] == value.String() {
+				value.SetString(forbiddenValue[lenght-1])
+				return nil
 			}
-			value.SetUint(defVal)
-			return nil
 		}
-	}
-
-	return nil
+		return nil
+	}, nil
 }
 
-func adaptForbiddenString(forbiddenValue []string, value reflect.Value) error {
-	lenght := len(forbiddenValue)
-	for i := 0; i < lenght-1; i++ {
-
-		if forbiddenValue[i] == value.String() {
-			value.SetString(forbiddenValue[lenght-1])
-			return nil
+func adaptForbiddenBool(forbiddenValue []string) (valueRule, error) {
+	values := make([]bool, len(forbiddenValue))
+	for i, option := range forbiddenValue {
+		val, err := strconv.ParseBool(option)
+		if err != nil {
+			return failRule(err)
 		}
+		values[i] = val
 	}
+	length := len(values)
 
-	return nil
+	return func(value reflect.Value) error {
+		for i := 0; i < length-1; i++ {
+			if values[i] == value.Bool() {
+				value.SetBool(values[length-1])
+				return nil
+			}
+		}
+		return nil
+	}, nil
 }
@@ -0,0 +1,232 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// compileMinLen parses rst-minlen once for values of type t.
+// Tag value is the minimal length, optionally followed by VAL_DELIMITER
+// and padding. Short strings are padded by repeating the padding or,
+// without it, replaced with rst-default. Short slices are padded with
+// the padding element or zero values, which element rules process after.
+// Length of strings is counted in runes.
+func compileMinLen(minLenValue tagValue, t reflect.Type, tags tagsList) (valueRule, error) {
+	rawLen, pad, hasPad := strings.Cut(string(minLenValue), VAL_DELIMITER)
+	minLen, err := parseLength(rawLen)
+	if err != nil {
+		return failRule(err)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if hasPad {
+			return adaptMinLenStringPad(minLen, pad)
+		}
+		return adaptMinLenStringDefault(minLen, tags)
+
+	case reflect.Slice:
+		return adaptMinLenSlice(minLen, pad, hasPad, t)
+
+	case reflect.Array:
+		// Длина массива фиксирована, проверяется только сам тег
+		if hasPad || minLen > t.Len() {
+			return failRule(ErrInvalidTags)
+		}
+		return func(reflect.Value) error {
+			return nil
+		}, nil
+
+	default:
+		return failRule(ErrInvalidTags)
+
+	}
+}
+
+func adaptMinLenStringPad(minLen int, pad string) (valueRule, error) {
+	padRunes := []rune(pad)
+	if len(padRunes) == 0 {
+		return failRule(ErrInvalidTags)
+	}
+
+	return func(value reflect.Value) error {
+		length := utf8.RuneCountInString(value.String())
+		if length >= minLen {
+			return nil
+		}
+
+		var b strings.Builder
+		b.WriteString(value.String())
+		for i := 0; length < minLen; i, length = i+1, length+1 {
+			b.WriteRune(padRunes[i%len(padRunes)])
+		}
+		value.SetString(b.String())
+		return nil
+	}, nil
+}
+
+func adaptMinLenStringDefault(minLen int, tags tagsList) (valueRule, error) {
+	defaultValue, ok := tags[RST_DEFAULT]
+	if !ok || utf8.RuneCountInString(string(defaultValue)) < minLen {
+		return failRule(fmt.Errorf("no padding and no default of length %d: %w", minLen, ErrInvalidTags))
+	}
+
+	return func(value reflect.Value) error {
+		if utf8.RuneCountInString(value.String()) < minLen {
+			value.SetString(string(defaultValue))
+		}
+		return nil
+	}, nil
+}
+
+func adaptMinLenSlice(minLen int, pad string, hasPad bool, t reflect.Type) (valueRule, error) {
+	padValue := reflect.New(t.Elem()).Elem()
+	if hasPad {
+		if err := setValue(tagValue(pad), padValue); err != nil {
+			return failRule(err)
+		}
+	}
+
+	return func(value reflect.Value) error {
+		if value.Len() >= minLen {
+			return nil
+		}
+
+		// Новый слайс, чтобы не затронуть общий с оригиналом массив
+		slice := reflect.MakeSlice(value.Type(), minLen, minLen)
+		reflect.Copy(slice, value)
+		for i := value.Len(); i < minLen; i++ {
+			slice.Index(i).Set(padValue)
+		}
+		value.Set(slice)
+		return nil
+	}, nil
+}
+
+// compileMaxLen parses rst-maxlen once for values of type t.
+// Long strings and slices are truncated, elements of arrays over
+// the limit are set to zero values, maps keep first keys in sorted order.
+// Length of strings is counted in runes.
+func compileMaxLen(maxLenValue tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
+	maxLen, err := parseLength(string(maxLenValue))
+	if err != nil {
+		return failRule(err)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return adaptMaxLenString(maxLen)
+
+	case reflect.Slice:
+		return func(value reflect.Value) error {
+			if value.Len() > maxLen {
+				value.Set(value.Slice(0, maxLen))
+			}
+			return nil
+		}, nil
+
+	case reflect.Array:
+		return func(value reflect.Value) error {
+			for i := maxLen; i < value.Len(); i++ {
+				value.Index(i).SetZero()
+			}
+			return nil
+		}, nil
+
+	case reflect.Map:
+		return adaptMaxLenMap(maxLen)
+
+	default:
+		return failRule(ErrInvalidTags)
+
+	}
+}
+
+func adaptMaxLenString(maxLen int) (valueRule, error) {
+	return func(value reflect.Value) error {
+		str := value.String()
+		length := 0
+		for pos := range str {
+			if length == maxLen {
+				value.SetString(str[:pos])
+				return nil
+			}
+			length++
+		}
+		return nil
+	}, nil
+}
+
+func adaptMaxLenMap(maxLen int) (valueRule, error) {
+	return func(value reflect.Value) error {
+		if value.Len() <= maxLen {
+			return nil
+		}
+
+		// Новая карта, чтобы не удалять ключи из общей с оригиналом
+		mapValue := reflect.MakeMapWithSize(value.Type(), maxLen)
+		for _, key := range sortedMapKeys(value)[:maxLen] {
+			mapValue.SetMapIndex(key, value.MapIndex(key))
+		}
+		value.Set(mapValue)
+		return nil
+	}, nil
+}
+
+func parseLength(rawLen string) (int, error) {
+	length, err := strconv.Atoi(rawLen)
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, ErrInvalidTags
+	}
+	return length, nil
+}
+
+// sortedMapKeys returns keys of the map in ascending order.
+func sortedMapKeys(mapValue reflect.Value) []reflect.Value {
+	keys := mapValue.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		x, y := keys[i], keys[j]
+		switch x.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return x.Int() < y.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return x.Uint() < y.Uint()
+		case reflect.Float32, reflect.Float64:
+			return x.Float() < y.Float()
+		case reflect.String:
+			return x.String() < y.String()
+		default:
+			return fmt.Sprint(x) < fmt.Sprint(y)
+		}
+	})
+	return keys
+}
+
+func isLengthRule(name tagName) bool {
+	return name == RST_MINLEN || name == RST_MAXLEN
+}
+
+func hasLengthRules(tags tagsList) bool {
+	_, hasMin := tags[RST_MINLEN]
+	_, hasMax := tags[RST_MAXLEN]
+	return hasMin || hasMax
+}
+
+// elementTags returns tags of elements of slices, arrays and maps:
+// length rules describe the container itself.
+func elementTags(tags tagsList) tagsList {
+	elements := make(tagsList, len(tags))
+	for tn, tv := range tags {
+		if !isLengthRule(tn) {
+			elements[tn] = tv
+		}
+	}
+	return elements
+}
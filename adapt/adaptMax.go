@@ -5,79 +5,86 @@ import (
 	"strconv"
 )
 
-func adaptMax(maxValue tagValue, value reflect.Value) (err error) {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return nil // Для nil указателей max не применяется
-		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
+// compileMax parses bound of rst-max once for values of type t.
+func compileMax(maxValue tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
+	switch t {
+	case durationType:
+		return adaptMaxDuration(maxValue)
+	case timeType:
+		return adaptMaxTime(maxValue)
 	}
 
-	switch value.Kind() {
+	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		err = adaptMaxInt(maxValue, value)
+		return adaptMaxInt(maxValue)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		err = adaptMaxUint(maxValue, value)
+		return adaptMaxUint(maxValue)
 
 	case reflect.Float64:
-		err = adaptMaxFloat64(maxValue, value)
+		return adaptMaxFloat64(maxValue)
 	case reflect.Float32:
-		err = adaptMaxFloat32(maxValue, value)
+		return adaptMaxFloat32(maxValue)
 
 	default:
-		err = ErrInvalidTags
+		return failRule(ErrInvalidTags)
 
 	}
-	return
 }
 
-func adaptMaxInt(maxValue tagValue, value reflect.Value) error {
+func adaptMaxInt(maxValue tagValue) (valueRule, error) {
 	max, err := strconv.Atoi(string(maxValue))
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Int() > int64(max) {
-		value.SetInt(int64(max))
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Int() > int64(max) {
+			value.SetInt(int64(max))
+		}
+		return nil
+	}, nil
 }
 
-func adaptMaxFloat64(maxValue tagValue, value reflect.Value) error {
+func adaptMaxFloat64(maxValue tagValue) (valueRule, error) {
 	max, err := strconv.ParseFloat(string(maxValue), 64)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Float() > max {
-		value.SetFloat(max)
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Float() > max {
+			value.SetFloat(max)
+		}
+		return nil
+	}, nil
 }
 
-func adaptMaxFloat32(maxValue tagValue, value reflect.Value) error {
+func adaptMaxFloat32(maxValue tagValue) (valueRule, error) {
 	max64, err := strconv.ParseFloat(string(maxValue), 32)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 	max := float32(max64)
-	if float32(value.Float()) > max {
-		value.SetFloat(float64(max))
-	}
-	return nil
+
+	return func(value reflect.Value) error {
+		if float32(value.Float()) > max {
+			value.SetFloat(float64(max))
+		}
+		return nil
+	}, nil
 }
 
-func adaptMaxUint(maxValue tagValue, value reflect.Value) error {
+func adaptMaxUint(maxValue tagValue) (valueRule, error) {
 	max, err := strconv.ParseUint(string(maxValue), 10, 64)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Uint() > max {
-		value.SetUint(max)
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Uint() > max {
+			value.SetUint(max)
+		}
+		return nil
+	}, nil
 }
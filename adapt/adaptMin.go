@@ -5,79 +5,86 @@ import (
 	"strconv"
 )
 
-func adaptMin(minValue tagValue, value reflect.Value) (err error) {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return nil // Для nil указателей min не применяется
-		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
+// compileMin parses bound of rst-min once for values of type t.
+func compileMin(minValue tagValue, t reflect.Type, _ tagsList) (valueRule, error) {
+	switch t {
+	case durationType:
+		return adaptMinDuration(minValue)
+	case timeType:
+		return adaptMinTime(minValue)
 	}
 
-	switch value.Kind() {
+	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		err = adaptMinInt(minValue, value)
+		return adaptMinInt(minValue)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		err = adaptMinUint(minValue, value)
+		return adaptMinUint(minValue)
 
 	case reflect.Float64:
-		err = adaptMinFloat64(minValue, value)
+		return adaptMinFloat64(minValue)
 	case reflect.Float32:
-		err = adaptMinFloat32(minValue, value)
+		return adaptMinFloat32(minValue)
 
 	default:
-		err = ErrInvalidTags
+		return failRule(ErrInvalidTags)
 
 	}
-	return
 }
 
-func adaptMinInt(minValue tagValue, value reflect.Value) error {
+func adaptMinInt(minValue tagValue) (valueRule, error) {
 	min, err := strconv.Atoi(string(minValue))
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Int() < int64(min) {
-		value.SetInt(int64(min))
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Int() < int64(min) {
+			value.SetInt(int64(min))
+		}
+		return nil
+	}, nil
 }
 
-func adaptMinFloat64(minValue tagValue, value reflect.Value) error {
+func adaptMinFloat64(minValue tagValue) (valueRule, error) {
 	min, err := strconv.ParseFloat(string(minValue), 64)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Float() < min {
-		value.SetFloat(min)
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Float() < min {
+			value.SetFloat(min)
+		}
+		return nil
+	}, nil
 }
 
-func adaptMinFloat32(minValue tagValue, value reflect.Value) error {
+func adaptMinFloat32(minValue tagValue) (valueRule, error) {
 	min64, err := strconv.ParseFloat(string(minValue), 32)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 	min := float32(min64)
-	if float32(value.Float()) < min {
-		value.SetFloat(float64(min))
-	}
-	return nil
+
+	return func(value reflect.Value) error {
+		if float32(value.Float()) < min {
+			value.SetFloat(float64(min))
+		}
+		return nil
+	}, nil
 }
 
-func adaptMinUint(minValue tagValue, value reflect.Value) error {
+func adaptMinUint(minValue tagValue) (valueRule, error) {
 	min, err := strconv.ParseUint(string(minValue), 10, 64)
 	if err != nil {
-		return err
+		return failRule(err)
 	}
 
-	if value.Uint() < min {
-		value.SetUint(min)
-	}
-	return nil
+	return func(value reflect.Value) error {
+		if value.Uint() < min {
+			value.SetUint(min)
+		}
+		return nil
+	}, nil
 }
@@ -1,27 +1,85 @@
 package adapt
 
 import (
+	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
+
+	"github.com/amakca/go-adapt/internal/structtag"
 )
 
-func adaptRegex(regexValue tagValue, value reflect.Value) error {
-	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr {
-		if value.IsNil() {
-			return nil // Для nil указателей regex не применяется
-		}
-		// Если указатель не nil, работаем с его значением
-		value = value.Elem()
-	}
+// Modes of rst-regex selected by prefix of the tag value.
+const (
+	REGEX_STRIP   = structtag.RegexStrip
+	REGEX_KEEP    = structtag.RegexKeep
+	REGEX_MATCH   = structtag.RegexMatch
+	REGEX_REPLACE = structtag.RegexReplace
+)
 
-	if value.Kind() != reflect.String {
-		return ErrInvalidTags
+// compileRegex compiles regular expression of rst-regex once.
+// Depending on the mode, matches are removed (strip, the default),
+// only matches are kept (keep), value not matching the expression
+// is replaced with fallback or rst-default (match), or matches are
+// replaced with template expanded as in regexp.ReplaceAllString (replace).
+func compileRegex(regexValue tagValue, t reflect.Type, tags tagsList) (valueRule, error) {
+	if t.Kind() != reflect.String {
+		return failRule(ErrInvalidTags)
 	}
-	regex, err := regexp.Compile(string(regexValue))
+
+	mode, pattern, arg, hasArg := structtag.ParseRegex(string(regexValue))
+	regex, err := regexp.Compile(pattern)
 	if err != nil {
-		return err
+		return failRule(err)
+	}
+
+	switch mode {
+	case REGEX_KEEP:
+		return adaptRegexKeep(regex)
+
+	case REGEX_MATCH:
+		if !hasArg {
+			defaultValue, ok := tags[RST_DEFAULT]
+			if !ok {
+				return failRule(fmt.Errorf("no fallback and no default: %w", ErrInvalidTags))
+			}
+			arg = string(defaultValue)
+		}
+		return adaptRegexMatch(regex, arg)
+
+	case REGEX_REPLACE:
+		if !hasArg {
+			return failRule(fmt.Errorf("no replacement: %w", ErrInvalidTags))
+		}
+		return adaptRegexReplace(regex, arg)
+
+	default:
+		return adaptRegexReplace(regex, "")
+
 	}
-	value.SetString(regex.ReplaceAllString(value.String(), ""))
-	return nil
+}
+
+func adaptRegexKeep(regex *regexp.Regexp) (valueRule, error) {
+	return func(value reflect.Value) error {
+		value.SetString(strings.Join(regex.FindAllString(value.String(), -1), ""))
+		return nil
+	}, nil
+}
+
+// adaptRegexMatch does not check zero values, as rst-choice.
+func adaptRegexMatch(regex *regexp.Regexp, fallback string) (valueRule, error) {
+	return func(value reflect.Value) error {
+		if value.IsZero() || regex.MatchString(value.String()) {
+			return nil
+		}
+		value.SetString(fallback)
+		return nil
+	}, nil
+}
+
+func adaptRegexReplace(regex *regexp.Regexp, template string) (valueRule, error) {
+	return func(value reflect.Value) error {
+		value.SetString(regex.ReplaceAllString(value.String(), template))
+		return nil
+	}, nil
 }
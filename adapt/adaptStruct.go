@@ -1,29 +1,61 @@
 package adapt
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"reflect"
 )
 
-type adapter struct {
-	logger *log.Logger
+// Adapter applies rules described in structure tags.
+// Zero value is ready to use: it has no logger and default options.
+type Adapter struct {
+	logger    *log.Logger
+	rules     []customRule
+	prefix    string
+	errorMode ErrorMode
+	naming    NamingStrategy
 }
 
-func New() adapter {
-	return adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+// New creates Adapter which logs changes to stdout, configured by options.
+// Like regexp.MustCompile, New panics if an option is invalid, e.g.
+// a custom rule name does not start with the tag prefix; NewE returns
+// the error instead.
+func New(opts ...Option) *Adapter {
+	a, err := NewE(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// NewE creates Adapter as New does, returning error of an invalid option,
+// which matches ErrInvalidRule or ErrRuleExists.
+func NewE(opts ...Option) (*Adapter, error) {
+	a := &Adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	// Правила проверяются после всех опций, так как имена зависят от префикса
+	rules := a.rules
+	a.rules = nil
+	for _, rule := range rules {
+		if err := a.addRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
 }
 
-func (a *adapter) SetLogger(l *log.Logger) {
+func (a *Adapter) SetLogger(l *log.Logger) {
 	a.logger = l
 }
 
-func (a *adapter) DisableLogger() {
+func (a *Adapter) DisableLogger() {
 	a.logger = nil
 }
 
-func (a *adapter) logf(format string, args ...any) {
+func (a *Adapter) logf(format string, args ...any) {
 	if a.logger == nil {
 		return
 	}
@@ -33,25 +65,132 @@ func (a *adapter) logf(format string, args ...any) {
 // AdaptStruct applies rules described in structure
 // tags to fields of input structure.
 // It takes as input pointer/value of structure, returns edited copy.
-func (a *adapter) AdaptStruct(input any) (any, error) {
+func (a *Adapter) AdaptStruct(input any) (any, error) {
+	return a.adaptStruct(input, a.newState())
+}
+
+func (a *Adapter) adaptStruct(input any, st *adaptState) (any, error) {
 	inputValue := reflect.ValueOf(input)
 
 	if reflect.Indirect(inputValue).Kind() != reflect.Struct {
 		return nil, ErrNotStruct
 	}
 
-	editedCopies := newStack()
+	if err := a.processField(inputValue, nil, st, ""); err != nil {
+		return nil, err
+	}
+	if err := st.err(); err != nil {
+		return nil, err
+	}
 
-	if err := a.processField(inputValue, "", editedCopies, ""); err != nil {
+	st.copies.applyChanges()
+
+	if err := a.adaptCross(st.copies.addrCopy, st); err != nil {
+		return nil, err
+	}
+	if err := st.err(); err != nil {
 		return nil, err
 	}
 
-	editedCopies.applyChanges()
+	// Правила со ссылками меняют уже примененную копию, поэтому структура
+	// по указателю обновляется повторно. Вложенные указатели копия разделяет
+	// с исходной структурой, и изменения за ними уже записаны
+	if inputValue.Kind() == reflect.Ptr {
+		inputValue.Elem().Set(st.copies.addrCopy)
+	}
 
-	return editedCopies.addrCopy.Interface(), nil
+	return st.copies.addrCopy.Interface(), nil
 }
 
-func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, editedCopies *stackEditedCopies, path string) error {
+// adaptState holds state of a single traversal of the input structure.
+type adaptState struct {
+	copies *stackEditedCopies
+
+	// dryRun disables modification of values: rules are applied to
+	// detached copies and every change is recorded as a violation.
+	dryRun     bool
+	violations []Violation
+
+	// inPlace makes rules modify values behind pointers
+	// instead of edited copies.
+	inPlace bool
+
+	// report enables recording of changes made by rules.
+	report  bool
+	changes []Change
+
+	// collect makes invalid tags not stop the traversal,
+	// errors are returned together at the end.
+	collect bool
+	errs    []*FieldError
+
+	// cross is set when fields with rules referencing other fields
+	// are found, adapted keeps values adapted in dry run for them.
+	cross   bool
+	adapted map[string][]reflect.Value
+
+	// paths keeps paths of fields joined once for elements
+	// of slices and maps, which share them.
+	paths map[pathKey]string
+}
+
+type pathKey struct {
+	parent, name string
+}
+
+// joinPath returns path of the field as joinPath does, reusing
+// paths joined before.
+func (st *adaptState) joinPath(parentPath, name string) string {
+	if parentPath == "" || name == "" {
+		return joinPath(parentPath, name)
+	}
+	key := pathKey{parent: parentPath, name: name}
+	if path, ok := st.paths[key]; ok {
+		return path
+	}
+	if st.paths == nil {
+		st.paths = make(map[pathKey]string)
+	}
+	path := joinPath(parentPath, name)
+	st.paths[key] = path
+	return path
+}
+
+func (a *Adapter) newState() *adaptState {
+	return &adaptState{
+		copies:  newStack(),
+		collect: a.errorMode == CollectErrors,
+	}
+}
+
+// err returns errors collected during the traversal as *FieldErrors.
+func (st *adaptState) err() error {
+	if len(st.errs) == 0 {
+		return nil
+	}
+	return &FieldErrors{Errors: st.errs}
+}
+
+// fail returns the error, or records it and returns nil
+// if errors are collected.
+func (st *adaptState) fail(err *FieldError) error {
+	if st.collect {
+		st.errs = append(st.errs, err)
+		return nil
+	}
+	return err
+}
+
+func (a *Adapter) processField(input reflect.Value, rules *fieldRules, st *adaptState, path string) error {
+
+	// Ненулевой указатель на bool отличает false от незаданного значения
+	if rules != nil && isSetBool(input) {
+		rules = rules.withoutDefault()
+	}
+
+	if st.inPlace && (input.Kind() == reflect.Ptr || input.Kind() == reflect.Interface) {
+		return a.processInPlace(input, rules, st, path)
+	}
 
 	if !input.CanAddr() || input.Kind() == reflect.Ptr || input.Kind() == reflect.Interface {
 		copyInput := makeCopy(input)
@@ -59,8 +198,8 @@ func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, edit
 		// Проверяем, что копия не пустая (для nil указателей)
 		if !copyInput.IsValid() {
 			// Для nil указателей применяем теги напрямую
-			if tags != "" {
-				if err := a.adaptValue(input, parseStructTag(tags), path); err != nil {
+			if rules != nil {
+				if err := a.adaptValue(input, rules, path, st); err != nil {
 					return err
 				}
 			}
@@ -68,58 +207,72 @@ func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, edit
 		}
 
 		if !input.CanAddr() {
-			editedCopies.updateCopy(copyInput)
+			st.copies.updateCopy(copyInput)
 		}
 
 		switch input.Kind() {
 		case reflect.Ptr:
 			if !input.IsNil() {
-				editedCopies.add(reflect.Indirect(input), copyInput)
+				st.copies.add(reflect.Indirect(input), copyInput)
 			}
 
 		case reflect.Interface:
 			if input.Elem().Kind() == reflect.Pointer {
-				editedCopies.add(input.Elem().Elem(), copyInput)
+				st.copies.add(input.Elem().Elem(), copyInput)
 			} else {
-				editedCopies.add(input, copyInput)
+				st.copies.add(input, copyInput)
 			}
 		}
 
 		input = copyInput
 	}
 
-	if input.Kind() == reflect.Struct {
-		if err := a.processFields(input, editedCopies, path); err != nil {
+	// Время обрабатывается правилами как простое значение
+	if input.Kind() == reflect.Struct && input.Type() != timeType {
+		if err := a.processFields(input, st, path); err != nil {
 			return err
 		}
-		return nil
+		return a.callHooks(input, path, st)
 	}
 
-	if tags == "" {
+	if rules == nil {
 		return nil
 	}
 
 	switch input.Kind() {
 	case reflect.Array, reflect.Slice:
+		if err := a.adaptLength(input, rules, path, st); err != nil {
+			return err
+		}
+		rules = rules.elements()
+
 		// Обрабатываем элементы слайса
 		for i := 0; i < input.Len(); i++ {
 
 			val := input.Index(i)
 
 			if isSimpleType(val) {
-				if err := a.adaptValue(val, parseStructTag(tags), path); err != nil {
+				if err := a.adaptValue(val, rules, path, st); err != nil {
 					return err
 				}
 			} else {
-				if err := a.processField(val, tags, editedCopies, path); err != nil {
+				if err := a.processField(val, rules, st, path); err != nil {
 					return err
 				}
 			}
 		}
 
 	case reflect.Map:
+		if err := a.adaptLength(input, rules, path, st); err != nil {
+			return err
+		}
+		rules = rules.elements()
+
 		// Создаем копию карты для работы с адресуемыми значениями
-		mapCopy := reflect.MakeMap(input.Type())
+		mapCopy := input
+		if !st.inPlace {
+			mapCopy = reflect.MakeMap(input.Type())
+		}
 
 		for _, key := range input.MapKeys() {
 			val := input.MapIndex(key)
@@ -129,11 +282,11 @@ func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, edit
 			valCopy.Set(val)
 
 			if isSimpleType(valCopy) {
-				if err := a.adaptValue(valCopy, parseStructTag(tags), path); err != nil {
+				if err := a.adaptValue(valCopy, rules, path, st); err != nil {
 					return err
 				}
 			} else {
-				if err := a.processField(valCopy, tags, editedCopies, path); err != nil {
+				if err := a.processField(valCopy, rules, st, path); err != nil {
 					return err
 				}
 			}
@@ -143,10 +296,12 @@ func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, edit
 		}
 
 		// Заменяем оригинальную карту копией
-		input.Set(mapCopy)
+		if !st.dryRun && !st.inPlace {
+			input.Set(mapCopy)
+		}
 
 	default:
-		if err := a.adaptValue(input, parseStructTag(tags), path); err != nil {
+		if err := a.adaptValue(input, rules, path, st); err != nil {
 			return err
 		}
 	}
@@ -154,55 +309,73 @@ func (a *adapter) processField(input reflect.Value, tags reflect.StructTag, edit
 	return nil
 }
 
+// processInPlace processes value behind pointer or interface
+// without copying it.
+func (a *Adapter) processInPlace(input reflect.Value, rules *fieldRules, st *adaptState, path string) error {
+	if input.IsNil() {
+		// Для nil значений применяем теги напрямую
+		if rules != nil {
+			return a.adaptValue(input, rules, path, st)
+		}
+		return nil
+	}
+
+	elem := input.Elem()
+	if input.Kind() == reflect.Ptr || elem.Kind() == reflect.Ptr {
+		return a.processField(elem, rules, st, path)
+	}
+
+	// Значение в интерфейсе не адресуемо: обрабатываем копию и записываем ее обратно
+	copyElem := reflect.New(elem.Type()).Elem()
+	copyElem.Set(elem)
+	if err := a.processField(copyElem, rules, st, path); err != nil {
+		return err
+	}
+	if input.CanSet() {
+		input.Set(copyElem)
+	}
+	return nil
+}
+
 // processFields iteratively processes fields of structure.
 // If field has struct tag, field will be processed accordingly.
 // If field is pointer or structure, processing will be
 // recursively called for them.
-func (a *adapter) processFields(input reflect.Value, editedCopies *stackEditedCopies, parentPath string) error {
-	inputType := input.Type()
-
-	for i := 0; i < input.NumField(); i++ {
-		field := inputType.Field(i)
-		value := input.Field(i)
-
-		name := field.Name
-		if jsonTag, ok := field.Tag.Lookup(TAG_JSON); ok && jsonTag != "" {
-			comma := len(jsonTag)
-			for i := 0; i < len(jsonTag); i++ {
-				if jsonTag[i] == ',' {
-					comma = i
-					break
-				}
-			}
-			if comma > 0 {
-				tagName := jsonTag[:comma]
-				if tagName != "-" && tagName != "" {
-					name = tagName
-				}
-			}
-		}
-		var path string
-		if parentPath == "" {
-			path = name
-		} else if name != "" {
-			path = parentPath + "." + name
-		} else {
-			path = parentPath
-		}
+func (a *Adapter) processFields(input reflect.Value, st *adaptState, parentPath string) error {
+	plan := a.planFor(input.Type())
+	if plan.cross {
+		st.cross = true
+	}
 
-		if err := a.processField(value, field.Tag, editedCopies, path); err != nil {
+	for _, field := range plan.fields {
+		path := st.joinPath(parentPath, field.name)
+		if err := a.processField(input.Field(field.index), field.rules, st, path); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// joinPath appends field name to the dotted path of its parent.
+func joinPath(parentPath, name string) string {
+	if parentPath == "" {
+		return name
+	}
+	if name == "" {
+		return parentPath
+	}
+	return parentPath + "." + name
+}
+
 func makeCopy(inputValue reflect.Value) reflect.Value {
 	if inputValue.Kind() == reflect.Interface {
 		inputValue = inputValue.Elem()
 	}
 
-	// Проверяем на nil указатели
+	// Проверяем на nil интерфейсы и указатели
+	if !inputValue.IsValid() {
+		return reflect.Value{}
+	}
 	if inputValue.Kind() == reflect.Ptr && inputValue.IsNil() {
 		return reflect.Value{}
 	}
@@ -216,43 +389,157 @@ func makeCopy(inputValue reflect.Value) reflect.Value {
 }
 
 // adaptValue takes as input value of structure field and
-// tag map for it. Processing method will be called for each tag.
-func (a *adapter) adaptValue(value reflect.Value, tagsList tagsList, path string) error {
+// rules of the field. Processing method will be called for each tag.
+func (a *Adapter) adaptValue(value reflect.Value, rules *fieldRules, path string, st *adaptState) error {
+	if st.dryRun {
+		// Input must stay untouched, so rules work with a detached copy
+		value = detachedCopy(value)
+		if st.adapted != nil && path != "" {
+			st.adapted[path] = append(st.adapted[path], value)
+		}
+	}
+
 	// Проверяем на nil указатели
-	if value.Kind() == reflect.Ptr && value.IsNil() {
-		// Для nil указателей применяем только default тег
-		if defaultTag, exists := tagsList[RST_DEFAULT]; exists {
-			if err := tagsMap[RST_DEFAULT](defaultTag, value); err != nil {
-				return err
-			}
-			if path != "" {
-				a.logf("field=%q reason=%q new_value=%v", path, RST_DEFAULT, indirectInterface(value))
-			}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			// Для nil указателей применяем только default тег
+			return a.adaptNilValue(value, rules, path, st)
 		}
-		return nil
+		value = value.Elem()
 	}
 
 	// Apply tags in deterministic priority order
-	ordered := []tagName{RST_DEFAULT, RST_MIN, RST_MAX, RST_CHOICE, RST_FORBIDDEN, RST_REGEX}
-	for _, tn := range ordered {
-		if tv, ok := tagsList[tn]; ok {
-			before := indirectInterface(value)
-			if err := tagsMap[tn](tv, value); err != nil {
-				if path != "" {
-					return fmt.Errorf("field %s, tag %s: %w", path, tn, err)
-				}
-				return err
+	for _, rule := range rules.forType(value.Type()) {
+		if err := a.applyRule(rule.name, rule.value, value, path, st, rule.apply); err != nil {
+			return err
+		}
+	}
+
+	// Custom rules run after built-in ones, in registration order
+	for _, rule := range a.rules {
+		if tv := rules.tag.Get(string(rule.name)); tv != "" {
+			apply := func(value reflect.Value) error {
+				return rule.apply(tv, value, path)
 			}
-			after := indirectInterface(value)
-			if path != "" && !reflect.DeepEqual(before, after) {
-				a.logf("field=%q reason=%q new_value=%v", path, tn, after)
+			if err := a.applyRule(rule.name, tagValue(tv), value, path, st, apply); err != nil {
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// adaptLength applies length rules to slice, array or map
+// before its elements are processed.
+func (a *Adapter) adaptLength(value reflect.Value, rules *fieldRules, path string, st *adaptState) error {
+	if !hasLengthRules(rules.tags) {
+		return nil
+	}
+	if st.dryRun {
+		value = detachedCopy(value)
+	}
+
+	for _, rule := range rules.forType(value.Type()) {
+		if !isLengthRule(rule.name) {
+			continue
+		}
+		if err := a.applyRule(rule.name, rule.value, value, path, st, rule.apply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adaptNilValue sets nil pointer to the new value with rst-default.
+func (a *Adapter) adaptNilValue(value reflect.Value, rules *fieldRules, path string, st *adaptState) error {
+	defaultTag, exists := rules.tags[RST_DEFAULT]
+	if !exists || rules.hasDefaultIf() {
+		return nil
+	}
+
+	elemType := value.Type().Elem()
+	var apply valueRule
+	for _, rule := range rules.forType(elemType) {
+		if rule.name == RST_DEFAULT {
+			apply = rule.apply
+		}
+	}
+
+	return a.applyRule(RST_DEFAULT, defaultTag, value, path, st, func(value reflect.Value) error {
+		newValue := reflect.New(elemType)
+		if err := apply(newValue.Elem()); err != nil {
+			return err
+		}
+		value.Set(newValue)
+		return nil
+	})
+}
+
+// applyRule calls rule function for the value and records the change it made.
+func (a *Adapter) applyRule(name tagName, tv tagValue, value reflect.Value, path string, st *adaptState, fn valueRule) error {
+	if !a.recordsChanges(st) {
+		if err := fn(value); err != nil {
+			return st.fail(&FieldError{Path: path, Tag: string(name), Value: string(tv), Err: err})
+		}
+		return nil
+	}
+
+	snap := takeSnapshot(value)
+	if err := fn(value); err != nil {
+		return st.fail(&FieldError{Path: path, Tag: string(name), Value: string(tv), Err: err})
+	}
+	if snap.equal(value) {
+		return nil
+	}
+	a.recordChange(name, tv, snap.value(value.Type()), value, path, st)
+	return nil
+}
+
+// recordsChanges reports whether changes made by rules are used: as
+// violations, in the report or in the log. Otherwise values are not
+// copied to find the changes, which saves allocations.
+func (a *Adapter) recordsChanges(st *adaptState) bool {
+	return st.dryRun || st.report || a.logger != nil
+}
+
+// recordChange records change of the value made by the rule
+// as a violation in dry run, otherwise reports and logs it.
+func (a *Adapter) recordChange(name tagName, tv tagValue, before any, value reflect.Value, path string, st *adaptState) {
+	after := indirectInterface(value)
+
+	if st.dryRun {
+		// Default only fills unset values, it is not a violation
+		if name != RST_DEFAULT && name != RST_DEFAULT_IF {
+			st.violations = append(st.violations, Violation{
+				Path:     path,
+				Rule:     string(name),
+				Value:    before,
+				Expected: after,
+			})
+		}
+		return
+	}
+
+	if st.report {
+		st.changes = append(st.changes, Change{
+			Path:     path,
+			Rule:     string(name),
+			Old:      before,
+			New:      after,
+			TagValue: string(tv),
+		})
+	}
+
+	if path != "" {
+		a.logf("field=%q reason=%q new_value=%v", path, name, after)
+	}
+}
+
 func isSimpleType(value reflect.Value) bool {
+	if value.Kind() == reflect.Struct && value.Type() == timeType {
+		return true
+	}
+
 	switch value.Kind() {
 	case
 		reflect.Int, reflect.Int8, reflect.Int16,
@@ -270,6 +557,25 @@ func isSimpleType(value reflect.Value) bool {
 	}
 }
 
+// isSetBool reports whether the value is a non-nil pointer to bool.
+func isSetBool(value reflect.Value) bool {
+	return value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Bool
+}
+
+// detachedCopy returns addressable copy of the value that does not
+// share memory with it, including value behind a pointer.
+func detachedCopy(value reflect.Value) reflect.Value {
+	copyValue := reflect.New(value.Type()).Elem()
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		elem := reflect.New(value.Type().Elem())
+		elem.Elem().Set(value.Elem())
+		copyValue.Set(elem)
+		return copyValue
+	}
+	copyValue.Set(value)
+	return copyValue
+}
+
 func indirectInterface(v reflect.Value) any {
 	if v.IsValid() && v.Kind() == reflect.Ptr && !v.IsNil() {
 		v = v.Elem()
@@ -0,0 +1,232 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TIME_NOW is the keyword of time relative to the moment
+// the rule is applied, e.g. "now", "now+24h" or "now-1h30m".
+const TIME_NOW = "now"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// isTimeType reports whether values of the type are time.Duration
+// or time.Time, which are written in tags in human form.
+func isTimeType(t reflect.Type) bool {
+	return t == durationType || t == timeType
+}
+
+// timeBound is a parsed time value of a tag. Relative
+// time is evaluated each time the rule is applied.
+type timeBound struct {
+	at       time.Time
+	relative bool
+	offset   time.Duration
+}
+
+// parseTimeBound parses time in RFC3339 format or relative to now.
+func parseTimeBound(raw string) (timeBound, error) {
+	raw = strings.TrimSpace(raw)
+	if rest, ok := strings.CutPrefix(raw, TIME_NOW); ok {
+		if rest == "" {
+			return timeBound{relative: true}, nil
+		}
+		if rest[0] != '+' && rest[0] != '-' {
+			return timeBound{}, fmt.Errorf("time %q: %w", raw, ErrInvalidTags)
+		}
+		offset, err := time.ParseDuration(rest)
+		if err != nil {
+			return timeBound{}, err
+		}
+		return timeBound{relative: true, offset: offset}, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return timeBound{}, err
+	}
+	return timeBound{at: at}, nil
+}
+
+func (b timeBound) time() time.Time {
+	if b.relative {
+		return time.Now().Add(b.offset)
+	}
+	return b.at
+}
+
+func parseTimeBounds(values []string) ([]timeBound, error) {
+	bounds := make([]timeBound, len(values))
+	for i, v := range values {
+		bound, err := parseTimeBound(v)
+		if err != nil {
+			return nil, err
+		}
+		bounds[i] = bound
+	}
+	return bounds, nil
+}
+
+// durationNanos converts durations like "1h30m" into nanoseconds,
+// so rules of integer values are reused for time.Duration.
+func durationNanos(values ...string) ([]string, error) {
+	nanos := make([]string, len(values))
+	for i, v := range values {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		nanos[i] = strconv.FormatInt(int64(d), 10)
+	}
+	return nanos, nil
+}
+
+func adaptMinDuration(minValue tagValue) (valueRule, error) {
+	nanos, err := durationNanos(string(minValue))
+	if err != nil {
+		return failRule(err)
+	}
+	return adaptMinInt(tagValue(nanos[0]))
+}
+
+func adaptMaxDuration(maxValue tagValue) (valueRule, error) {
+	nanos, err := durationNanos(string(maxValue))
+	if err != nil {
+		return failRule(err)
+	}
+	return adaptMaxInt(tagValue(nanos[0]))
+}
+
+func adaptMinTime(minValue tagValue) (valueRule, error) {
+	bound, err := parseTimeBound(string(minValue))
+	if err != nil {
+		return failRule(err)
+	}
+
+	return func(value reflect.Value) error {
+		min := bound.time()
+		if value.Interface().(time.Time).Before(min) {
+			value.Set(reflect.ValueOf(min))
+		}
+		return nil
+	}, nil
+}
+
+func adaptMaxTime(maxValue tagValue) (valueRule, error) {
+	bound, err := parseTimeBound(string(maxValue))
+	if err != nil {
+		return failRule(err)
+	}
+
+	return func(value reflect.Value) error {
+		max := bound.time()
+		if value.Interface().(time.Time).After(max) {
+			value.Set(reflect.ValueOf(max))
+		}
+		return nil
+	}, nil
+}
+
+// adaptDefaultTime evaluates relative default each time it is applied.
+func adaptDefaultTime(defaultValue tagValue) (valueRule, error) {
+	bound, err := parseTimeBound(string(defaultValue))
+
+	return func(value reflect.Value) error {
+		if !value.IsZero() {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(bound.time()))
+		return nil
+	}, err
+}
+
+func adaptChoiceDuration(set []string) (valueRule, error) {
+	nanos, err := durationNanos(set...)
+	if err != nil {
+		return failRule(err)
+	}
+	return adaptChoiceInt(nanos)
+}
+
+func adaptChoiceTime(set []string) (valueRule, error) {
+	bounds, err := parseTimeBounds(set)
+	if err != nil {
+		return failRule(err)
+	}
+
+	return func(value reflect.Value) error {
+		current := value.Interface().(time.Time)
+		for _, bound := range bounds {
+			if current.Equal(bound.time()) {
+				return nil
+			}
+		}
+
+		value.Set(reflect.ValueOf(bounds[0].time()))
+		return nil
+	}, nil
+}
+
+func adaptForbiddenDuration(forbiddenValue []string) (valueRule, error) {
+	nanos, err := durationNanos(forbiddenValue...)
+	if err != nil {
+		return failRule(err)
+	}
+	return adaptForbiddenInt(nanos)
+}
+
+func adaptForbiddenTime(forbiddenValue []string) (valueRule, error) {
+	bounds, err := parseTimeBounds(forbiddenValue)
+	if err != nil {
+		return failRule(err)
+	}
+	length := len(bounds)
+
+	return func(value reflect.Value) error {
+		current := value.Interface().(time.Time)
+		for i := 0; i < length-1; i++ {
+			if current.Equal(bounds[i].time()) {
+				value.Set(reflect.ValueOf(bounds[length-1].time()))
+				return nil
+			}
+		}
+		return nil
+	}, nil
+}
+
+// setTimeValue parses duration or time for setValue.
+func setTimeValue(rawValue tagValue, value reflect.Value) error {
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(strings.TrimSpace(string(rawValue)))
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	bound, err := parseTimeBound(string(rawValue))
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(bound.time()))
+	return nil
+}
+
+// formatTimeValue returns duration or time in the form used in tags.
+func formatTimeValue(value reflect.Value) string {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String()
+	}
+	return value.Interface().(time.Time).Format(time.RFC3339Nano)
+}
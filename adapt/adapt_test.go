@@ -3,7 +3,11 @@ package adapt
 import (
 	"log"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -114,7 +118,7 @@ type ArrayTestStruct struct {
 	StringArray [2]string  `rst-regex:"[^x]+"`
 }
 
-var a = adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
+var a = Adapter{logger: log.New(os.Stdout, "adapter ", log.LstdFlags)}
 
 func Test_AdaptIncorrectInput(t *testing.T) {
 	uintValue := uint(2)
@@ -512,6 +516,672 @@ func Test_ExtendedRegex(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expected, result)
 	})
+
+	t.Run("Explicit Strip Mode", func(t *testing.T) {
+		type TestStruct struct {
+			Phone string `rst-regex:"strip:[^0-9+]"`
+			Keep  string `rst-regex:"strip:keep:"`
+		}
+		test := TestStruct{Phone: "+7 (900) 123-45-67", Keep: "keep:value"}
+		expected := TestStruct{Phone: "+79001234567", Keep: "value"}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Keep Mode", func(t *testing.T) {
+		type TestStruct struct {
+			Name string `rst-regex:"keep:[a-zA-Z]+"`
+			Zip  string `rst-regex:"keep:[0-9]{5}"`
+		}
+		test := TestStruct{Name: "John123 Doe", Zip: "zip 123"}
+		expected := TestStruct{Name: "JohnDoe", Zip: ""}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Match Mode", func(t *testing.T) {
+		type TestStruct struct {
+			Email    string `rst-regex:"match:^[a-z.]+@[a-z]+\\.[a-z]{2,}$**"`
+			Zip      string `rst-regex:"match:^[0-9]{5}$**00000"`
+			Host     string `rst-regex:"match:^[a-z.]+$" rst-default:"localhost"`
+			Optional string `rst-regex:"match:^[0-9]+$**0"`
+		}
+		tests := []struct {
+			input    TestStruct
+			expected TestStruct
+		}{
+			{
+				input:    TestStruct{Email: "john.doe@example.com", Zip: "12345", Host: "example.com"},
+				expected: TestStruct{Email: "john.doe@example.com", Zip: "12345", Host: "example.com"},
+			},
+			{
+				input:    TestStruct{Email: "invalid-email", Zip: "123", Host: "Example.com", Optional: "abc"},
+				expected: TestStruct{Email: "", Zip: "00000", Host: "localhost", Optional: "0"},
+			},
+		}
+
+		for _, tt := range tests {
+			result, err := a.AdaptStruct(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		}
+	})
+
+	t.Run("Replace Mode", func(t *testing.T) {
+		type TestStruct struct {
+			Date  string `rst-regex:"replace:^(\\d{2})\\.(\\d{2})\\.(\\d{4})$**$3-$2-$1"`
+			Space string `rst-regex:"replace:\\s+** "`
+		}
+		test := TestStruct{Date: "31.12.2024", Space: "a  b\tc"}
+		expected := TestStruct{Date: "2024-12-31", Space: "a b c"}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Invalid Modes", func(t *testing.T) {
+		type NoFallback struct {
+			Value string `rst-regex:"match:^[0-9]+$"`
+		}
+		type NoTemplate struct {
+			Value string `rst-regex:"replace:[0-9]"`
+		}
+
+		_, err := a.AdaptStruct(NoFallback{Value: "1"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		_, err = a.AdaptStruct(NoTemplate{Value: "1"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+}
+
+func Test_ExtendedLength(t *testing.T) {
+	t.Run("String Max Length", func(t *testing.T) {
+		type TestStruct struct {
+			Name string `rst-maxlen:"5"`
+		}
+		test := TestStruct{Name: "Привет, мир"}
+		expected := TestStruct{Name: "Приве"}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("String Min Length Padding", func(t *testing.T) {
+		type TestStruct struct {
+			Code string `rst-minlen:"6**0"`
+			Name string `rst-minlen:"5**-+"`
+		}
+		test := TestStruct{Code: "42", Name: "ab"}
+		expected := TestStruct{Code: "420000", Name: "ab-+-"}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("String Min Length Default", func(t *testing.T) {
+		type TestStruct struct {
+			Token string `rst-minlen:"8" rst-default:"changeme"`
+		}
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{"", "changeme"},
+			{"abc", "changeme"},
+			{"abcdefgh", "abcdefgh"},
+		}
+
+		for _, tt := range tests {
+			result, err := a.AdaptStruct(TestStruct{Token: tt.input})
+			assert.NoError(t, err)
+			assert.Equal(t, TestStruct{Token: tt.expected}, result)
+		}
+	})
+
+	t.Run("String Min Length Without Fallback", func(t *testing.T) {
+		type NoDefault struct {
+			Token string `rst-minlen:"8"`
+		}
+		type ShortDefault struct {
+			Token string `rst-minlen:"8" rst-default:"short"`
+		}
+
+		_, err := a.AdaptStruct(NoDefault{Token: "abcdefgh"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		_, err = a.AdaptStruct(ShortDefault{Token: "abc"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+
+	t.Run("Slice Length", func(t *testing.T) {
+		type TestStruct struct {
+			Max     []string `rst-maxlen:"2" rst-regex:"[0-9]"`
+			Pad     []int    `rst-minlen:"3**8080"`
+			Default []string `rst-minlen:"2" rst-default:"localhost"`
+			Nil     []int    `rst-minlen:"1"`
+			Short   []string `rst-maxlen:"3"`
+		}
+		test := TestStruct{
+			Max:     []string{"a1", "b2", "c3"},
+			Pad:     []int{80},
+			Default: []string{"example.com"},
+			Short:   []string{"abcdef"},
+		}
+		expected := TestStruct{
+			Max:     []string{"a", "b"},
+			Pad:     []int{80, 8080, 8080},
+			Default: []string{"example.com", "localhost"},
+			Nil:     []int{0},
+			Short:   []string{"abcdef"},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, []int{80}, test.Pad)
+		assert.Equal(t, []string{"example.com"}, test.Default)
+	})
+
+	t.Run("Array Length", func(t *testing.T) {
+		type TestStruct struct {
+			Values [4]int `rst-maxlen:"2"`
+		}
+		type Invalid struct {
+			Values [2]int `rst-minlen:"3"`
+		}
+
+		result, err := a.AdaptStruct(TestStruct{Values: [4]int{1, 2, 3, 4}})
+		assert.NoError(t, err)
+		assert.Equal(t, TestStruct{Values: [4]int{1, 2, 0, 0}}, result)
+
+		_, err = a.AdaptStruct(Invalid{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+
+	t.Run("Map Max Length", func(t *testing.T) {
+		type TestStruct struct {
+			Limits map[string]int `rst-maxlen:"2" rst-max:"10"`
+		}
+		test := TestStruct{Limits: map[string]int{"c": 30, "a": 10, "b": 20}}
+		expected := TestStruct{Limits: map[string]int{"a": 10, "b": 10}}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Len(t, test.Limits, 3)
+	})
+
+	t.Run("Invalid Length", func(t *testing.T) {
+		type Negative struct {
+			Name string `rst-maxlen:"-1"`
+		}
+		type NotContainer struct {
+			Value int `rst-maxlen:"1"`
+		}
+		type MapMin struct {
+			Values map[string]int `rst-minlen:"1"`
+		}
+
+		_, err := a.AdaptStruct(Negative{Name: "a"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		_, err = a.AdaptStruct(NotContainer{Value: 1})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		_, err = a.AdaptStruct(MapMin{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		type TestStruct struct {
+			Name  string   `rst-maxlen:"3"`
+			Hosts []string `rst-maxlen:"1"`
+		}
+		test := TestStruct{Name: "abcdef", Hosts: []string{"a", "b"}}
+
+		err := a.Validate(test)
+		var verr *ValidationError
+		if assert.ErrorAs(t, err, &verr) {
+			assert.Len(t, verr.Violations, 2)
+			assert.Equal(t, "Hosts", verr.Violations[1].Path)
+			assert.Equal(t, RST_MAXLEN, verr.Violations[1].Rule)
+		}
+		assert.Equal(t, TestStruct{Name: "abcdef", Hosts: []string{"a", "b"}}, test)
+	})
+}
+
+func Test_TimeAdaptation(t *testing.T) {
+	t.Run("Duration", func(t *testing.T) {
+		type TestStruct struct {
+			Timeout   time.Duration   `rst-min:"1s" rst-max:"1h30m"`
+			Interval  time.Duration   `rst-default:"500ms"`
+			Retry     time.Duration   `rst-choice:"1s||5s||10s"`
+			Backoff   time.Duration   `rst-forbidden:"0s||1ns**100ms"`
+			Deadline  *time.Duration  `rst-default:"2m"`
+			Intervals []time.Duration `rst-max:"1m"`
+		}
+		test := TestStruct{
+			Timeout:   2 * time.Hour,
+			Retry:     3 * time.Second,
+			Backoff:   time.Nanosecond,
+			Intervals: []time.Duration{time.Second, time.Hour},
+		}
+		deadline := 2 * time.Minute
+		expected := TestStruct{
+			Timeout:   90 * time.Minute,
+			Interval:  500 * time.Millisecond,
+			Retry:     time.Second,
+			Backoff:   100 * time.Millisecond,
+			Deadline:  &deadline,
+			Intervals: []time.Duration{time.Second, time.Minute},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+
+		result, err = a.AdaptStruct(TestStruct{Timeout: time.Millisecond})
+		assert.NoError(t, err)
+		assert.Equal(t, time.Second, result.(TestStruct).Timeout)
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		type TestStruct struct {
+			Start   time.Time   `rst-min:"2024-01-01T00:00:00Z" rst-max:"2025-01-01T00:00:00Z"`
+			Release time.Time   `rst-default:"2024-06-01T12:00:00+03:00"`
+			Window  time.Time   `rst-choice:"2024-01-01T00:00:00Z||2024-07-01T00:00:00Z"`
+			Epoch   time.Time   `rst-forbidden:"1970-01-01T00:00:00Z**2000-01-01T00:00:00Z"`
+			Expires *time.Time  `rst-default:"2030-01-01T00:00:00Z"`
+			Dates   []time.Time `rst-min:"2024-01-01T00:00:00Z"`
+		}
+		start := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+		test := TestStruct{
+			Start:  start,
+			Window: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Epoch:  time.Unix(0, 0),
+			Dates:  []time.Time{start, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		adapted := result.(TestStruct)
+
+		assert.True(t, adapted.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, adapted.Release.Equal(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)))
+		assert.True(t, adapted.Window.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, adapted.Epoch.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+		if assert.NotNil(t, adapted.Expires) {
+			assert.Equal(t, 2030, adapted.Expires.Year())
+		}
+		assert.True(t, adapted.Dates[0].Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, adapted.Dates[1].Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, start, test.Start)
+	})
+
+	t.Run("Relative Time", func(t *testing.T) {
+		type TestStruct struct {
+			Expires time.Time `rst-default:"now+24h" rst-max:"now+48h"`
+			Created time.Time `rst-max:"now"`
+		}
+		before := time.Now()
+		test := TestStruct{Created: before.Add(time.Hour)}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		adapted := result.(TestStruct)
+
+		assert.WithinRange(t, adapted.Expires, before.Add(24*time.Hour), time.Now().Add(24*time.Hour))
+		assert.WithinRange(t, adapted.Created, before, time.Now())
+
+		result, err = a.AdaptStruct(TestStruct{Expires: before.Add(72 * time.Hour)})
+		assert.NoError(t, err)
+		assert.WithinRange(t, result.(TestStruct).Expires, before.Add(48*time.Hour), time.Now().Add(48*time.Hour))
+	})
+
+	t.Run("Invalid Tags", func(t *testing.T) {
+		type InvalidDuration struct {
+			Timeout time.Duration `rst-min:"10"`
+		}
+		type InvalidTime struct {
+			Start time.Time `rst-min:"2024-01-01"`
+		}
+		type InvalidRelative struct {
+			Start time.Time `rst-default:"now24h"`
+		}
+
+		_, err := a.AdaptStruct(InvalidDuration{})
+		assert.Error(t, err)
+		_, err = a.AdaptStruct(InvalidTime{})
+		assert.Error(t, err)
+		_, err = a.AdaptStruct(InvalidRelative{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+}
+
+func Test_BoolAdaptation(t *testing.T) {
+	type Flags struct {
+		Enabled  bool    `rst-default:"true"`
+		Debug    *bool   `rst-default:"true"`
+		Metrics  *bool   `rst-default:"false"`
+		Strict   bool    `rst-choice:"true"`
+		Legacy   bool    `rst-forbidden:"true**false"`
+		Features []*bool `rst-default:"true"`
+	}
+	boolPtr := func(v bool) *bool { return &v }
+
+	t.Run("Default", func(t *testing.T) {
+		result, err := a.AdaptStruct(Flags{})
+		assert.NoError(t, err)
+		assert.Equal(t, Flags{Enabled: true, Debug: boolPtr(true), Metrics: boolPtr(false)}, result)
+	})
+
+	t.Run("Pointer To False Is Set", func(t *testing.T) {
+		test := Flags{Debug: boolPtr(false), Features: []*bool{nil, boolPtr(false)}}
+		expected := Flags{
+			Enabled:  true,
+			Debug:    boolPtr(false),
+			Metrics:  boolPtr(false),
+			Features: []*bool{boolPtr(true), boolPtr(false)},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+
+		test = Flags{Debug: boolPtr(false), Features: []*bool{nil, boolPtr(false)}}
+		changes, err := a.AdaptInPlace(&test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, test)
+		assert.Len(t, changes, 3)
+	})
+
+	t.Run("Choice And Forbidden", func(t *testing.T) {
+		result, err := a.AdaptStruct(Flags{Strict: true, Legacy: true})
+		assert.NoError(t, err)
+		assert.True(t, result.(Flags).Strict)
+		assert.False(t, result.(Flags).Legacy)
+	})
+
+	t.Run("Invalid Tags", func(t *testing.T) {
+		type InvalidBool struct {
+			Enabled bool `rst-default:"yes"`
+		}
+		_, err := a.AdaptStruct(InvalidBool{})
+		assert.Error(t, err)
+	})
+}
+
+func Test_CrossFieldRules(t *testing.T) {
+	type Pool struct {
+		MinConns int `json:"min_conns" rst-min:"1"`
+		MaxConns int `json:"max_conns" rst-min:"@pool.min_conns" rst-max:"100"`
+	}
+	type TLS struct {
+		Enabled bool   `json:"enabled"`
+		Key     string `json:"key" rst-required-if:"@tls.enabled"`
+		Mode    string `json:"mode" rst-default-if:"@tls.enabled**strict"`
+	}
+	type Config struct {
+		Pool      Pool          `json:"pool"`
+		TLS       *TLS          `json:"tls"`
+		Env       string        `json:"env"`
+		LogLevel  string        `json:"log_level" rst-default-if:"@env=prod**warn" rst-default:"debug"`
+		Port      int           `json:"port" rst-max:"@port_end"`
+		PortEnd   int           `json:"port_end" rst-default:"9000" rst-max:"@limit"`
+		Limit     int64         `json:"limit" rst-default:"8000"`
+		Timeout   time.Duration `json:"timeout" rst-max:"@max_wait"`
+		MaxWait   time.Duration `json:"max_wait" rst-default:"10s"`
+		StartedAt *time.Time    `json:"started_at" rst-min:"@not_before"`
+		NotBefore time.Time     `json:"not_before"`
+	}
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	startedAt := notBefore.Add(-time.Hour)
+
+	t.Run("Adapt", func(t *testing.T) {
+		test := Config{
+			Pool:      Pool{MinConns: 10, MaxConns: 5},
+			TLS:       &TLS{Enabled: true, Key: "key.pem"},
+			Env:       "prod",
+			Port:      10000,
+			Timeout:   time.Minute,
+			StartedAt: &startedAt,
+			NotBefore: notBefore,
+		}
+
+		result, changes, err := a.AdaptStructWithReport(test)
+		assert.NoError(t, err)
+		config := result.(Config)
+		assert.Equal(t, Pool{MinConns: 10, MaxConns: 10}, config.Pool)
+		assert.Equal(t, "strict", config.TLS.Mode)
+		assert.Equal(t, "warn", config.LogLevel)
+		assert.Equal(t, 8000, config.PortEnd)
+		assert.Equal(t, 8000, config.Port)
+		assert.Equal(t, 10*time.Second, config.Timeout)
+		assert.Equal(t, notBefore, *config.StartedAt)
+
+		// Поля со ссылками обрабатываются после тех, на которые ссылаются
+		var order []string
+		for _, change := range changes {
+			if strings.HasPrefix(string(change.TagValue), REF_PREFIX) {
+				order = append(order, change.Path)
+			}
+		}
+		assert.Equal(t, []string{"pool.max_conns", "tls.mode", "log_level", "port_end", "port", "timeout", "started_at"}, order)
+	})
+
+	t.Run("Conditions Not Hold", func(t *testing.T) {
+		result, err := a.AdaptStruct(Config{TLS: &TLS{}, Env: "dev"})
+		assert.NoError(t, err)
+		config := result.(Config)
+		assert.Equal(t, "", config.TLS.Mode)
+		assert.Equal(t, "debug", config.LogLevel)
+		assert.Nil(t, config.StartedAt)
+
+		// Ссылки на поля за nil указателем пропускаются
+		type Optional struct {
+			TLS  *TLS   `json:"tls"`
+			Key  string `json:"key" rst-required-if:"@tls.enabled"`
+			Keys []TLS  `json:"keys"`
+			Name string `json:"name" rst-default-if:"@keys.enabled**none"`
+		}
+		_, err = a.AdaptStruct(Optional{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		_, err := a.AdaptStruct(Config{TLS: &TLS{Enabled: true}})
+		assert.ErrorIs(t, err, ErrRequired)
+		assert.Contains(t, err.Error(), "field tls.key, tag rst-required-if")
+
+		err = a.Validate(Config{TLS: &TLS{Enabled: true}})
+		assert.ErrorIs(t, err, ErrRequired)
+	})
+
+	t.Run("In Place", func(t *testing.T) {
+		test := Config{Pool: Pool{MinConns: 10}, TLS: &TLS{Enabled: true, Key: "k"}}
+
+		_, err := a.AdaptInPlace(&test)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, test.Pool.MaxConns)
+		assert.Equal(t, "strict", test.TLS.Mode)
+	})
+
+	t.Run("Pointer", func(t *testing.T) {
+		type Bounds struct {
+			Min int `json:"min"`
+			Max int `json:"max" rst-min:"@min"`
+		}
+		test := Config{Pool: Pool{MinConns: 10}, TLS: &TLS{Enabled: true, Key: "k"}, Port: 10000}
+
+		result, err := a.AdaptStruct(&test)
+		assert.NoError(t, err)
+		assert.Equal(t, test, result)
+		assert.Equal(t, 10, test.Pool.MaxConns)
+		assert.Equal(t, "strict", test.TLS.Mode)
+		assert.Equal(t, 8000, test.Port)
+
+		bounds := Bounds{Min: 3, Max: 1}
+		_, err = a.AdaptStruct(&bounds)
+		assert.NoError(t, err)
+		assert.Equal(t, Bounds{Min: 3, Max: 3}, bounds)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		test := Config{
+			Pool:    Pool{MinConns: 10, MaxConns: 20},
+			Env:     "dev",
+			Port:    9500,
+			PortEnd: 10000,
+			Limit:   9000,
+			MaxWait: time.Second,
+		}
+
+		err := a.Validate(test)
+		var verr *ValidationError
+		if assert.ErrorAs(t, err, &verr) {
+			// port сравнивается с уже исправленным значением port_end
+			assert.Equal(t, []Violation{
+				{Path: "port_end", Rule: RST_MAX, Value: 10000, Expected: 9000},
+				{Path: "port", Rule: RST_MAX, Value: 9500, Expected: 9000},
+			}, verr.Violations)
+		}
+		assert.Equal(t, 10000, test.PortEnd)
+	})
+
+	t.Run("Slices And Maps", func(t *testing.T) {
+		type Range struct {
+			From int `json:"from"`
+			To   int `json:"to" rst-min:"@limits.from"`
+		}
+		type Limits struct {
+			Limits Range            `json:"limits"`
+			Ranges []Range          `json:"ranges"`
+			ByName map[string]Range `json:"by_name"`
+		}
+		test := Limits{
+			Limits: Range{From: 5, To: 1},
+			Ranges: []Range{{To: 1}, {To: 10}},
+			ByName: map[string]Range{"a": {To: 2}},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		limits := result.(Limits)
+		assert.Equal(t, Range{From: 5, To: 5}, limits.Limits)
+		assert.Equal(t, []Range{{To: 5}, {To: 10}}, limits.Ranges)
+		assert.Equal(t, Range{To: 5}, limits.ByName["a"])
+	})
+
+	t.Run("Same Element", func(t *testing.T) {
+		type Server struct {
+			Lo int `json:"lo"`
+			Hi int `json:"hi" rst-min:"@servers.lo"`
+		}
+		type Pool struct {
+			Lo int `json:"lo"`
+			Hi int `json:"hi" rst-min:"@pools.lo"`
+		}
+		type Cluster struct {
+			Servers []Server        `json:"servers"`
+			Pools   map[string]Pool `json:"pools"`
+		}
+		test := Cluster{
+			Servers: []Server{{Lo: 5, Hi: 1}, {Lo: 2, Hi: 3}, {Lo: 7, Hi: 6}},
+			Pools:   map[string]Pool{"a": {Lo: 4, Hi: 1}, "b": {Lo: 1, Hi: 9}},
+		}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		cluster := result.(Cluster)
+		assert.Equal(t, []Server{{Lo: 5, Hi: 5}, {Lo: 2, Hi: 3}, {Lo: 7, Hi: 7}}, cluster.Servers)
+		assert.Equal(t, map[string]Pool{"a": {Lo: 4, Hi: 4}, "b": {Lo: 1, Hi: 9}}, cluster.Pools)
+		assert.NoError(t, CheckType(reflect.TypeOf(Cluster{})))
+	})
+
+	t.Run("Lossy Bounds", func(t *testing.T) {
+		type Ratio struct {
+			Ratio float64 `json:"ratio"`
+			Count int     `json:"count" rst-min:"@ratio"`
+		}
+		type Signed struct {
+			Delta int  `json:"delta"`
+			Size  uint `json:"size" rst-max:"@delta"`
+		}
+
+		result, err := a.AdaptStruct(Ratio{Ratio: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, Ratio{Ratio: 3, Count: 3}, result)
+
+		_, err = a.AdaptStruct(Ratio{Ratio: 2.7})
+		var ferr *FieldError
+		if assert.ErrorAs(t, err, &ferr) {
+			assert.Equal(t, "count", ferr.Path)
+			assert.Equal(t, RST_MIN, ferr.Tag)
+		}
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+		_, err = a.AdaptStruct(Signed{Delta: -1, Size: 5})
+		assert.ErrorIs(t, err, strconv.ErrRange)
+		assert.ErrorContains(t, err, "field size, tag rst-max: -1 overflows uint")
+	})
+
+	t.Run("Invalid References", func(t *testing.T) {
+		type Item struct {
+			Value int `json:"value"`
+		}
+		type Unknown struct {
+			Max int `json:"max" rst-min:"@missing"`
+		}
+		type Ambiguous struct {
+			Items []Item `json:"items"`
+			Max   int    `json:"max" rst-min:"@items.value"`
+		}
+		type Incomparable struct {
+			Name string `json:"name"`
+			Max  int    `json:"max" rst-min:"@name"`
+		}
+		type NoDefault struct {
+			On   bool   `json:"on"`
+			Mode string `json:"mode" rst-default-if:"@on"`
+		}
+		type NoReference struct {
+			Key string `json:"key" rst-required-if:"on"`
+		}
+
+		for _, input := range []any{
+			Unknown{},
+			Ambiguous{Items: []Item{{}, {}}},
+			Incomparable{},
+			NoDefault{On: true},
+			NoReference{},
+		} {
+			_, err := a.AdaptStruct(input)
+			assert.ErrorIs(t, err, ErrInvalidTags, "%T", input)
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		type Cycle struct {
+			A    int `json:"a" rst-min:"@b"`
+			B    int `json:"b" rst-min:"@c"`
+			C    int `json:"c" rst-max:"@a"`
+			Self int `json:"self" rst-min:"@self"`
+			Free int `json:"free" rst-min:"@a"`
+		}
+
+		_, err := a.AdaptStruct(Cycle{})
+		assert.ErrorIs(t, err, ErrRuleCycle)
+		assert.Contains(t, err.Error(), "field a: cyclic field references: a -> b -> c -> a")
+
+		_, err = New(WithLogger(nil), WithErrorMode(CollectErrors)).AdaptStruct(Cycle{})
+		assert.ErrorIs(t, err, ErrRuleCycle)
+		assert.Contains(t, err.Error(), "field a: cyclic field references: a -> b -> c -> a")
+		assert.Contains(t, err.Error(), "field self: cyclic field references: self -> self")
+	})
 }
 
 func Test_ExtendedCombined(t *testing.T) {
@@ -825,6 +1495,17 @@ func Test_EdgeCases(t *testing.T) {
 		assert.Equal(t, *expected.FloatPtr, *result.(TestStruct).FloatPtr)
 	})
 
+	t.Run("Nil Interface", func(t *testing.T) {
+		type TestStruct struct {
+			Value any
+		}
+		test := TestStruct{}
+
+		result, err := a.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, test, result)
+	})
+
 	t.Run("Empty Slice", func(t *testing.T) {
 		type TestStruct struct {
 			IntSlice []int `rst-min:"5"`
@@ -884,3 +1565,81 @@ func Test_ParseStructTag(t *testing.T) {
 		assert.Equal(t, test, result)
 	})
 }
+
+func Test_CustomRules(t *testing.T) {
+	type TestStruct struct {
+		Name  string   `json:"name" rst-lowercase:"true" info:"Имя"`
+		Tags  []string `rst-lowercase:"true"`
+		Port  int      `rst-port:"8080"`
+		Empty string
+	}
+
+	newAdapter := func(t *testing.T) Adapter {
+		ca := Adapter{}
+		err := ca.RegisterRule("rst-lowercase", func(tv string, value reflect.Value, path string) error {
+			if value.Kind() != reflect.String {
+				return ErrInvalidTags
+			}
+			value.SetString(strings.ToLower(value.String()))
+			return nil
+		})
+		assert.NoError(t, err)
+		err = ca.RegisterRule("rst-port", func(tv string, value reflect.Value, path string) error {
+			if value.Int() <= 0 || value.Int() > 65535 {
+				port, err := strconv.Atoi(tv)
+				if err != nil {
+					return err
+				}
+				value.SetInt(int64(port))
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		return ca
+	}
+
+	t.Run("Apply Custom Rules", func(t *testing.T) {
+		ca := newAdapter(t)
+		test := TestStruct{Name: "JoHn", Tags: []string{"A", "b"}, Port: 70000}
+
+		result, err := ca.AdaptStruct(test)
+		assert.NoError(t, err)
+		assert.Equal(t, TestStruct{Name: "john", Tags: []string{"a", "b"}, Port: 8080}, result)
+	})
+
+	t.Run("Custom Rule Error", func(t *testing.T) {
+		ca := newAdapter(t)
+		type Invalid struct {
+			Count int `rst-lowercase:"true"`
+		}
+
+		_, err := ca.AdaptStruct(Invalid{Count: 1})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.Contains(t, err.Error(), "rst-lowercase")
+	})
+
+	t.Run("Invalid Registration", func(t *testing.T) {
+		ca := newAdapter(t)
+		noop := func(string, reflect.Value, string) error { return nil }
+
+		assert.ErrorIs(t, ca.RegisterRule("lowercase", noop), ErrInvalidRule)
+		assert.ErrorIs(t, ca.RegisterRule("rst-min", noop), ErrRuleExists)
+		assert.ErrorIs(t, ca.RegisterRule("rst-port", noop), ErrRuleExists)
+		assert.ErrorIs(t, ca.RegisterRule("rst-nil", nil), ErrInvalidRule)
+		assert.ErrorIs(t, ca.RegisterRuleComment("rst-unknown", func(string) string { return "" }), ErrInvalidRule)
+	})
+
+	t.Run("Custom Rule Comment", func(t *testing.T) {
+		ca := newAdapter(t)
+		err := ca.RegisterRuleComment("rst-lowercase", func(string) string { return "в нижнем регистре" })
+		assert.NoError(t, err)
+
+		yaml, err := ca.GenerateStructYAML(TestStruct{})
+		assert.NoError(t, err)
+		assert.Contains(t, yaml, "# Имя; в нижнем регистре\n  name: \"\"")
+
+		yaml, err = GenerateStructYAML(TestStruct{})
+		assert.NoError(t, err)
+		assert.NotContains(t, yaml, "в нижнем регистре")
+	})
+}
@@ -0,0 +1,392 @@
+package adapt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/amakca/go-adapt/internal/structtag"
+)
+
+// CheckType statically checks tags of the structure type t and of nested
+// structures without a value, so errors which adaptation reports only for
+// values triggering them are found at once, e.g. in a unit test or init().
+// It reports as *FieldErrors tags which cannot be parsed for the type
+// of the field, unknown rules with the tag prefix, contradictory tags
+// (rst-min greater than rst-max, rst-default or rst-choice values changed
+// by other rules of the field) and invalid references to other fields.
+// All reported errors wrap ErrInvalidTags, except cycles of references
+// reported with ErrRuleCycle.
+func CheckType(t reflect.Type) error {
+	return new(Adapter).CheckType(t)
+}
+
+// CheckField checks tags of a single field of type t,
+// see method CheckField of Adapter.
+func CheckField(t reflect.Type, tag reflect.StructTag) error {
+	return new(Adapter).CheckField(t, tag)
+}
+
+// MustCheck checks tags of the structure type T as CheckType does
+// and panics if they are invalid.
+func MustCheck[T any]() {
+	if err := CheckType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		panic(err)
+	}
+}
+
+// CheckType checks tags as the package function does, with the tag
+// prefix, naming strategy and custom rules of the adapter.
+func (a *Adapter) CheckType(t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	c := a.newTypeChecker()
+	c.checkStruct(t, "", nil)
+	c.checkCross()
+	return c.err()
+}
+
+// CheckField checks tags of a single field of type t as CheckType does,
+// except references to other fields, which depend on the adapted structure.
+// It is meant for tools checking tags without the whole structure type,
+// reported errors have empty Path.
+func (a *Adapter) CheckField(t reflect.Type, tag reflect.StructTag) error {
+	c := a.newTypeChecker()
+	c.checkUnknownTags(tag, "")
+
+	rules := newFieldRules(tag, a.tagPrefix())
+	if rules != nil {
+		for _, rule := range rules.cross {
+			if err := checkCrossValue(rule, derefType(t)); err != nil {
+				c.fail("", rule.name, rule.value, invalidTag(err))
+			}
+		}
+	}
+	c.checkField(t, rules, "", nil)
+	return c.err()
+}
+
+func (a *Adapter) newTypeChecker() *typeChecker {
+	return &typeChecker{
+		a:       a,
+		checked: make(map[reflect.Type]bool),
+		walking: make(map[reflect.Type]bool),
+		fields:  make(map[string]staticField),
+		cs:      &crossState{refs: make(map[string][]fieldRef), cross: make(map[string]bool)},
+	}
+}
+
+func (c *typeChecker) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return &FieldErrors{Errors: c.errs}
+}
+
+// staticField is a field found by its path when walking the type.
+type staticField struct {
+	t          reflect.Type // type of the value, without pointers
+	containers []string     // paths of slices, arrays and maps containing the field
+}
+
+type typeChecker struct {
+	a *Adapter
+
+	// Rules of a structure type are reported once, for the first path
+	// of the type. Recursive types are walked to the first repetition.
+	checked map[reflect.Type]bool
+	walking map[reflect.Type]bool
+	quiet   int
+
+	fields map[string]staticField
+	cs     *crossState // fields with cross rules, used for ordering
+	errs   []*FieldError
+}
+
+func (c *typeChecker) fail(path string, tag tagName, tv tagValue, err error) {
+	if c.quiet > 0 {
+		return
+	}
+	c.errs = append(c.errs, &FieldError{Path: path, Tag: string(tag), Value: string(tv), Err: err})
+}
+
+func (c *typeChecker) checkStruct(t reflect.Type, path string, containers []string) {
+	if c.walking[t] {
+		return
+	}
+	c.walking[t] = true
+	defer delete(c.walking, t)
+
+	// Пути полей запоминаются для каждого вхождения типа, а ошибки только для первого
+	if c.checked[t] {
+		c.quiet++
+		defer func() { c.quiet-- }()
+	}
+	c.checked[t] = true
+
+	for _, field := range c.a.planFor(t).fields {
+		structField := t.Field(field.index)
+		if !structField.IsExported() {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.name)
+		c.fields[fieldPath] = staticField{t: derefType(structField.Type), containers: containers}
+		c.checkUnknownTags(structField.Tag, fieldPath)
+
+		if field.rules != nil && len(field.rules.cross) > 0 {
+			c.cs.refs[fieldPath] = append(c.cs.refs[fieldPath], fieldRef{rules: field.rules})
+			if !c.cs.cross[fieldPath] {
+				c.cs.cross[fieldPath] = true
+				c.cs.paths = append(c.cs.paths, fieldPath)
+			}
+		}
+		c.checkField(structField.Type, field.rules, fieldPath, containers)
+	}
+}
+
+// checkField checks rules of the field as processField applies them.
+func (c *typeChecker) checkField(t reflect.Type, rules *fieldRules, path string, containers []string) {
+	t = derefType(t)
+
+	switch {
+	case t.Kind() == reflect.Struct && t != timeType:
+		if rules != nil {
+			for _, tn := range tagsOrder {
+				if tv, ok := rules.tags[tn]; ok {
+					c.fail(path, tn, tv, fmt.Errorf("rule is not applied to structures: %w", ErrInvalidTags))
+				}
+			}
+		}
+		c.checkStruct(t, path, containers)
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+		if rules != nil {
+			c.checkLength(t, rules, path)
+			rules = rules.elements()
+		}
+		c.checkField(t.Elem(), rules, path, append(slices.Clip(containers), path))
+
+	case t.Kind() == reflect.Interface:
+		// Тип значения заранее неизвестен
+
+	case rules != nil:
+		c.checkRules(t, rules, path)
+
+	}
+}
+
+// checkLength checks length rules of slices, arrays and maps.
+func (c *typeChecker) checkLength(t reflect.Type, rules *fieldRules, path string) {
+	for _, tn := range []tagName{RST_MINLEN, RST_MAXLEN} {
+		if tv, ok := rules.tags[tn]; ok {
+			if _, err := tagsMap[tn](tv, t, rules.tags); err != nil {
+				c.fail(path, tn, tv, invalidTag(err))
+			}
+		}
+	}
+	c.checkLengthBounds(rules, path)
+}
+
+// checkRules checks rules applied to values of simple type t.
+func (c *typeChecker) checkRules(t reflect.Type, rules *fieldRules, path string) {
+	valid := true
+	for _, tn := range tagsOrder {
+		if tv, ok := rules.tags[tn]; ok {
+			if _, err := tagsMap[tn](tv, t, rules.tags); err != nil {
+				c.fail(path, tn, tv, invalidTag(err))
+				valid = false
+			}
+		}
+	}
+	c.checkLengthBounds(rules, path)
+
+	// Противоречия ищутся только для корректных тегов, относительное время
+	// вычисляется при применении и не сравнивается
+	if !valid || t == timeType {
+		return
+	}
+
+	compiled := rules.forType(t)
+	if tv, ok := rules.tags[RST_DEFAULT]; ok {
+		c.checkLiteral(t, compiled, path, RST_DEFAULT, tv, string(tv), RST_DEFAULT)
+	}
+	if tv, ok := rules.tags[RST_CHOICE]; ok {
+		for _, option := range strings.Split(string(tv), SET_DELIMITER) {
+			c.checkLiteral(t, compiled, path, RST_CHOICE, tv, option, RST_DEFAULT, RST_CHOICE)
+		}
+	}
+	if tv, ok := rules.tags[RST_FORBIDDEN]; ok {
+		_, replacement, _ := strings.Cut(string(tv), VAL_DELIMITER)
+		c.checkLiteral(t, compiled, path, RST_FORBIDDEN, tv, replacement, RST_DEFAULT, RST_CHOICE)
+	}
+	if minValue, ok := rules.tags[RST_MIN]; ok {
+		if maxValue, ok := rules.tags[RST_MAX]; ok {
+			min := reflect.New(t).Elem()
+			if setValue(minValue, min) == nil && c.changedBy(min, compiled, RST_MAX) != "" {
+				c.fail(path, RST_MIN, minValue, fmt.Errorf("greater than %s %s: %w", RST_MAX, maxValue, ErrInvalidTags))
+			}
+		}
+	}
+}
+
+// checkLiteral reports value of the tag changed by other rules of the field,
+// e.g. rst-default outside of rst-min and rst-max. Rules in skip are not applied.
+func (c *typeChecker) checkLiteral(t reflect.Type, compiled []boundRule, path string, tn tagName, tv tagValue, raw string, skip ...tagName) {
+	value := reflect.New(t).Elem()
+	if err := setValue(tagValue(raw), value); err != nil {
+		return
+	}
+
+	rules := make([]boundRule, 0, len(compiled))
+	for _, rule := range compiled {
+		if !slices.Contains(skip, rule.name) {
+			rules = append(rules, rule)
+		}
+	}
+	if rule := c.changedBy(value, rules); rule != "" {
+		c.fail(path, tn, tv, fmt.Errorf("value %s is changed by %s: %w", raw, rule, ErrInvalidTags))
+	}
+}
+
+// changedBy applies rules to the value and returns name of the first rule
+// changing it. Without names all rules are applied.
+func (c *typeChecker) changedBy(value reflect.Value, rules []boundRule, names ...tagName) tagName {
+	for _, rule := range rules {
+		if len(names) > 0 && !slices.Contains(names, rule.name) {
+			continue
+		}
+		snap := takeSnapshot(value)
+		if rule.apply(value) == nil && !snap.equal(value) {
+			return rule.name
+		}
+	}
+	return ""
+}
+
+func (c *typeChecker) checkLengthBounds(rules *fieldRules, path string) {
+	minValue, hasMin := rules.tags[RST_MINLEN]
+	maxValue, hasMax := rules.tags[RST_MAXLEN]
+	if !hasMin || !hasMax {
+		return
+	}
+
+	rawMin, _, _ := strings.Cut(string(minValue), VAL_DELIMITER)
+	minLen, minErr := parseLength(rawMin)
+	maxLen, maxErr := parseLength(string(maxValue))
+	if minErr == nil && maxErr == nil && minLen > maxLen {
+		c.fail(path, RST_MINLEN, minValue, fmt.Errorf("greater than %s %s: %w", RST_MAXLEN, maxValue, ErrInvalidTags))
+	}
+}
+
+// checkUnknownTags reports tags with the prefix which are neither
+// built-in nor registered rules, e.g. misspelled rst-mni.
+func (c *typeChecker) checkUnknownTags(tag reflect.StructTag, path string) {
+	prefix := c.a.tagPrefix()
+	for _, key := range structtag.Keys(tag) {
+		if !strings.HasPrefix(key, prefix) || c.a.knownRule(key) {
+			continue
+		}
+		c.fail(path, tagName(key), tagValue(tag.Get(key)), fmt.Errorf("unknown rule: %w", ErrInvalidTags))
+	}
+}
+
+// checkCross checks references of cross rules and their cycles.
+func (c *typeChecker) checkCross() {
+	for _, path := range c.cs.paths {
+		field := c.fields[path]
+		for _, ref := range c.cs.refs[path] {
+			for _, rule := range ref.rules.cross {
+				if err := c.checkCrossRule(rule, field); err != nil {
+					c.fail(path, rule.name, rule.value, invalidTag(err))
+				}
+			}
+		}
+	}
+
+	_, errs := c.cs.order()
+	c.errs = append(c.errs, errs...)
+}
+
+// checkCrossRule checks the rule of the field. Fields in elements of
+// slices and maps are referenced unambiguously only from the same elements.
+func (c *typeChecker) checkCrossRule(rule crossRule, field staticField) error {
+	t := field.t
+	if err := checkCrossValue(rule, t); err != nil {
+		return err
+	}
+
+	ref, ok := c.fields[rule.ref]
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown field %s", rule.ref)
+	case !containsAll(field.containers, ref.containers):
+		return fmt.Errorf("ambiguous field %s in elements of slice or map", rule.ref)
+	}
+
+	if rule.name == RST_MIN || rule.name == RST_MAX {
+		if orderedClass(t) == 0 || orderedClass(t) != orderedClass(ref.t) {
+			return fmt.Errorf("cannot compare %s with %s", t, ref.t)
+		}
+	}
+	if rule.hasCond {
+		return setValue(tagValue(rule.cond), reflect.New(ref.t).Elem())
+	}
+	return nil
+}
+
+// containsAll reports whether all paths of sub are in paths.
+func containsAll(paths, sub []string) bool {
+	for _, path := range sub {
+		if !slices.Contains(paths, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCrossValue checks parts of the cross rule which do not depend
+// on the referenced field: syntax and value of rst-default-if.
+func checkCrossValue(rule crossRule, t reflect.Type) error {
+	if rule.err != nil {
+		return rule.err
+	}
+	if rule.name == RST_DEFAULT_IF {
+		return setValue(tagValue(rule.arg), reflect.New(t).Elem())
+	}
+	return nil
+}
+
+// knownRule reports whether the tag key is a built-in or registered rule.
+func (a *Adapter) knownRule(key string) bool {
+	for _, names := range [][]tagName{tagsOrder, condTags} {
+		for _, name := range names {
+			if key == tagKey(name, a.tagPrefix()) {
+				return true
+			}
+		}
+	}
+	return a.customRule(tagName(key)) != nil
+}
+
+// invalidTag wraps parse errors with ErrInvalidTags.
+func invalidTag(err error) error {
+	if errors.Is(err, ErrInvalidTags) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidTags, err)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,169 @@
+package adapt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckType(t *testing.T) {
+	// checkErrors returns path and tag of every error reported for type of v
+	checkErrors := func(t *testing.T, ca *Adapter, v any) [][2]string {
+		err := ca.CheckType(reflect.TypeOf(v))
+		if err == nil {
+			return nil
+		}
+		var ferrs *FieldErrors
+		if !assert.ErrorAs(t, err, &ferrs) {
+			return nil
+		}
+		got := make([][2]string, len(ferrs.Errors))
+		for i, ferr := range ferrs.Errors {
+			got[i] = [2]string{ferr.Path, ferr.Tag}
+		}
+		return got
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		type Node struct {
+			Name string `json:"name" rst-maxlen:"16"`
+			Next *Node  `json:"next"`
+		}
+		type Config struct {
+			Port     int               `json:"port" rst-min:"1024" rst-max:"65535" rst-default:"8080"`
+			Level    string            `json:"level" rst-choice:"info||debug" rst-default:"info"`
+			Hosts    []string          `json:"hosts" rst-minlen:"1" rst-maxlen:"3" rst-default:"localhost"`
+			Labels   map[string]string `json:"labels" rst-maxlen:"10"`
+			Timeout  time.Duration     `json:"timeout" rst-min:"1s" rst-default:"30s"`
+			Expires  time.Time         `json:"expires" rst-default:"now+24h" rst-max:"now+48h"`
+			Node     *Node             `json:"node"`
+			Any      any               `json:"any" rst-min:"1"`
+			MinConns int               `json:"min_conns"`
+			MaxConns int               `json:"max_conns" rst-min:"@min_conns"`
+			private  int               `rst-min:"abc"`
+		}
+
+		assert.NoError(t, CheckType(reflect.TypeOf(Config{})))
+		assert.NoError(t, CheckType(reflect.TypeOf(&Config{})))
+		assert.NotPanics(t, MustCheck[Config])
+		assert.NoError(t, CheckType(reflect.TypeOf(ExampleStruct{})))
+		assert.NoError(t, CheckType(reflect.TypeOf(Order{})))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		type Inner struct {
+			Count int `json:"count" rst-max:"many"`
+		}
+		type Config struct {
+			Min       int      `json:"min" rst-min:"abc"`
+			Forbidden int      `json:"forbidden" rst-forbidden:"1||2"`
+			Regex     int      `json:"regex" rst-regex:"[0-9]"`
+			Default   bool     `json:"default" rst-default:"yes"`
+			Typo      int      `json:"typo" rst-mni:"1"`
+			MapMinLen []string `json:"map" rst-minlen:"-1"`
+			Struct    Inner    `json:"struct" rst-default:"1"`
+			Items     []Inner  `json:"items"`
+		}
+
+		assert.Equal(t, [][2]string{
+			{"min", RST_MIN},
+			{"forbidden", RST_FORBIDDEN},
+			{"regex", RST_REGEX},
+			{"default", RST_DEFAULT},
+			{"typo", "rst-mni"},
+			{"map", RST_MINLEN},
+			{"struct", RST_DEFAULT},
+			{"struct.count", RST_MAX},
+		}, checkErrors(t, new(Adapter), Config{}))
+
+		err := CheckType(reflect.TypeOf(Config{}))
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.Panics(t, MustCheck[Config])
+	})
+
+	t.Run("Contradictions", func(t *testing.T) {
+		type Config struct {
+			Port      int           `json:"port" rst-min:"1024" rst-max:"100"`
+			Default   int           `json:"default" rst-min:"10" rst-max:"20" rst-default:"5"`
+			Choice    string        `json:"choice" rst-choice:"info||trace" rst-forbidden:"trace**info"`
+			Forbidden float64       `json:"forbidden" rst-max:"1" rst-forbidden:"0.5**2"`
+			Length    string        `json:"length" rst-minlen:"8**0" rst-maxlen:"4"`
+			Name      string        `json:"name" rst-maxlen:"3" rst-default:"unknown"`
+			Timeout   time.Duration `json:"timeout" rst-max:"1s" rst-default:"1m"`
+		}
+
+		assert.Equal(t, [][2]string{
+			{"port", RST_MIN},
+			{"default", RST_DEFAULT},
+			{"choice", RST_CHOICE},
+			{"forbidden", RST_FORBIDDEN},
+			{"length", RST_MINLEN},
+			{"name", RST_DEFAULT},
+			{"timeout", RST_DEFAULT},
+		}, checkErrors(t, new(Adapter), Config{}))
+	})
+
+	t.Run("References", func(t *testing.T) {
+		type Item struct {
+			Value int `json:"value"`
+		}
+		type Config struct {
+			Unknown   int    `json:"unknown" rst-min:"@missing"`
+			Ambiguous int    `json:"ambiguous" rst-max:"@items.value"`
+			Items     []Item `json:"items"`
+			Last      Item   `json:"last"`
+			Max       int    `json:"max" rst-max:"@last.value"`
+			Name      string `json:"name"`
+			Compare   int    `json:"compare" rst-min:"@name"`
+			Cond      string `json:"cond" rst-required-if:"@unknown=abc"`
+			Default   int    `json:"default" rst-default-if:"@name**abc"`
+			A         int    `json:"a" rst-min:"@b"`
+			B         int    `json:"b" rst-min:"@a"`
+		}
+
+		assert.Equal(t, [][2]string{
+			{"unknown", RST_MIN},
+			{"ambiguous", RST_MAX},
+			{"compare", RST_MIN},
+			{"cond", RST_REQUIRED_IF},
+			{"default", RST_DEFAULT_IF},
+			{"a", ""},
+		}, checkErrors(t, new(Adapter), Config{}))
+
+		err := CheckType(reflect.TypeOf(Config{}))
+		assert.ErrorIs(t, err, ErrRuleCycle)
+	})
+
+	t.Run("Adapter Options", func(t *testing.T) {
+		type Config struct {
+			Port  int    `json:"port" cfg-min:"abc"`
+			Lower string `json:"lower" cfg-lower:"true" cfg-upper:"true"`
+		}
+		noop := func(string, reflect.Value, string) error { return nil }
+		ca := New(WithTagPrefix("cfg-"), WithNaming(NamingField), WithRule("cfg-lower", noop))
+
+		assert.Equal(t, [][2]string{
+			{"Port", RST_MIN},
+			{"Lower", "cfg-upper"},
+		}, checkErrors(t, ca, Config{}))
+	})
+
+	t.Run("Field", func(t *testing.T) {
+		assert.NoError(t, CheckField(reflect.TypeOf(0), `rst-min:"1" rst-max:"@other"`))
+
+		err := CheckField(reflect.TypeOf(""), `rst-min:"1" rst-default-if:"@on" rst-maxln:"2"`)
+		var ferrs *FieldErrors
+		if assert.ErrorAs(t, err, &ferrs) && assert.Len(t, ferrs.Errors, 3) {
+			assert.Equal(t, FieldError{Tag: "rst-maxln", Value: "2", Err: ferrs.Errors[0].Err}, *ferrs.Errors[0])
+			assert.Equal(t, RST_DEFAULT_IF, ferrs.Errors[1].Tag)
+			assert.Equal(t, RST_MIN, ferrs.Errors[2].Tag)
+		}
+	})
+
+	t.Run("Not Struct", func(t *testing.T) {
+		assert.ErrorIs(t, CheckType(reflect.TypeOf(1)), ErrNotStruct)
+		assert.ErrorIs(t, CheckType(nil), ErrNotStruct)
+	})
+}
@@ -3,29 +3,48 @@ package adapt
 import (
 	"errors"
 	"reflect"
+
+	"github.com/amakca/go-adapt/internal/structtag"
 )
 
 type tagName string
 type tagValue string
 
-type tagFunction func(tagValue, reflect.Value) error
+// valueRule applies rule with already parsed tag value to the value.
+type valueRule func(reflect.Value) error
+
+// ruleCompiler parses tag value once for values of the given type.
+// Other tags of the field are passed for rules depending on them.
+// Returned rule reports parse error when applied, so the error
+// surfaces only for values the rule is actually applied to.
+type ruleCompiler func(tagValue, reflect.Type, tagsList) (valueRule, error)
 
 type tagsList map[tagName]tagValue
 
 const (
 	SET_DELIMITER = "||"
-	VAL_DELIMITER = "**"
+	VAL_DELIMITER = structtag.ValDelimiter
 
 	TAG_VALUE = "value"
 	TAG_JSON  = "json"
 	TAG_INFO  = "info"
 
-	RST_MIN       = "rst-min"
-	RST_MAX       = "rst-max"
-	RST_REGEX     = "rst-regex"
-	RST_DEFAULT   = "rst-default"
-	RST_CHOICE    = "rst-choice"
-	RST_FORBIDDEN = "rst-forbidden"
+	RST_PREFIX    = "rst-"
+	RST_MIN       = structtag.RuleMin
+	RST_MAX       = structtag.RuleMax
+	RST_REGEX     = structtag.RuleRegex
+	RST_DEFAULT   = structtag.RuleDefault
+	RST_CHOICE    = structtag.RuleChoice
+	RST_FORBIDDEN = structtag.RuleForbidden
+	RST_MINLEN    = structtag.RuleMinLen
+	RST_MAXLEN    = structtag.RuleMaxLen
+
+	// Rules depending on values of other fields
+	RST_DEFAULT_IF  = "rst-default-if"
+	RST_REQUIRED_IF = "rst-required-if"
+
+	// REF_PREFIX marks dotted path of the referenced field in tag values
+	REF_PREFIX = "@"
 
 	// removed unused VLD_* constants
 )
@@ -33,17 +52,40 @@ const (
 var (
 	ErrNotStruct   = errors.New("argument is not a struct")
 	ErrInvalidTags = errors.New("invalid struct tags")
+	ErrInvalidRule = errors.New("invalid rule")
+	ErrRuleExists  = errors.New("rule already registered")
+	ErrRequired    = errors.New("required field is not set")
+	ErrRuleCycle   = errors.New("cyclic field references")
 )
 
-var tagsMap = map[tagName]tagFunction{
-	RST_MIN:       adaptMin,
-	RST_MAX:       adaptMax,
-	RST_REGEX:     adaptRegex,
-	RST_DEFAULT:   adaptDefault,
-	RST_CHOICE:    adaptChoice,
-	RST_FORBIDDEN: adaptForbidden,
+var tagsMap = map[tagName]ruleCompiler{
+	RST_MIN:       compileMin,
+	RST_MAX:       compileMax,
+	RST_REGEX:     compileRegex,
+	RST_DEFAULT:   compileDefault,
+	RST_CHOICE:    compileChoice,
+	RST_FORBIDDEN: compileForbidden,
+	RST_MINLEN:    compileMinLen,
+	RST_MAXLEN:    compileMaxLen,
 }
 
+// tagsOrder is the order in which built-in rules are applied,
+// shared with code generated by adaptgen.
+var tagsOrder = func() []tagName {
+	order := make([]tagName, len(structtag.RulesOrder))
+	for i, name := range structtag.RulesOrder {
+		order[i] = tagName(name)
+	}
+	return order
+}()
+
+// condTags are built-in rules applied only when a condition
+// on another field holds, they have no simple form.
+var condTags = []tagName{RST_DEFAULT_IF, RST_REQUIRED_IF}
+
+// crossOrder is the order in which rules referencing other fields are applied.
+var crossOrder = []tagName{RST_DEFAULT_IF, RST_MIN, RST_MAX, RST_REQUIRED_IF}
+
 // В последние 3 тега добавить разделители для строковых значений
 // Разобраться с float32
 // Добавить в тесты пустые поля, проверить на конфликт тегов
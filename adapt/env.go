@@ -0,0 +1,186 @@
+package adapt
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	TAG_ENV = "env"
+
+	ENV_SEPARATOR     = ","
+	ENV_KEY_DELIMITER = "="
+)
+
+// LoadEnv overrides fields of structure pointed to by out with values
+// of environment variables and applies rules described in structure tags.
+//
+// Variable name is taken from env tag, env:"-" excludes the field.
+// If prefix is not empty, fields without env tag are read from variables
+// named after prefix and dotted path of the field: path server.port with
+// prefix APP is read from APP_SERVER_PORT. With empty prefix only fields
+// with env tag are read.
+//
+// Values are parsed the same way as rst-default. Slices are read as
+// values separated by ENV_SEPARATOR, maps as KEY=VAL pairs separated
+// by ENV_SEPARATOR.
+func (a *Adapter) LoadEnv(out any, prefix string) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	if _, err := a.loadEnvFields(outValue.Elem(), prefix, "", skipMark, map[reflect.Type]bool{}); err != nil {
+		return err
+	}
+
+	_, err := a.AdaptInPlace(out)
+	return err
+}
+
+// loadEnvFields sets fields of the structure from environment
+// variables, calling mark with their paths and values, and reports
+// whether any variable was found. Types on the path are tracked
+// to stop on recursive types, as GenerateEnvTemplate does.
+func (a *Adapter) loadEnvFields(input reflect.Value, prefix, parentPath string, mark func(path, raw string), seen map[reflect.Type]bool) (bool, error) {
+	inputType := input.Type()
+	found := false
+
+	seen[inputType] = true
+	defer delete(seen, inputType)
+
+	for i := 0; i < input.NumField(); i++ {
+		field := inputType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		path := joinPath(parentPath, a.naming.fieldName(field))
+		name, explicit := field.Tag.Lookup(TAG_ENV)
+		if name == "-" {
+			continue
+		}
+
+		value := input.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// Вложенные структуры обрабатываются рекурсивно,
+		// nil указатель создается только при наличии переменных
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !explicit {
+			if seen[fieldType] {
+				continue
+			}
+			nested := reflect.New(fieldType).Elem()
+			if value.Kind() == reflect.Ptr && !value.IsNil() || value.Kind() == reflect.Struct {
+				nested.Set(reflect.Indirect(value))
+			}
+			ok, err := a.loadEnvFields(nested, prefix, path, mark, seen)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				setIndirect(value, nested)
+				found = true
+			}
+			continue
+		}
+
+		if !explicit {
+			if prefix == "" {
+				continue
+			}
+			name = envName(prefix, path)
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		parsed := reflect.New(fieldType).Elem()
+		if err := setEnvValue(raw, parsed); err != nil {
+			return false, fmt.Errorf("env %s: field %s: %w", name, path, err)
+		}
+		setIndirect(value, parsed)
+		mark(path, raw)
+		found = true
+	}
+
+	return found, nil
+}
+
+// setEnvValue parses value of environment variable into the value.
+func setEnvValue(raw string, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Slice:
+		items := splitEnvList(raw)
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(tagValue(item), slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+
+	case reflect.Map:
+		items := splitEnvList(raw)
+		mapValue := reflect.MakeMapWithSize(value.Type(), len(items))
+		for _, item := range items {
+			key, val, ok := strings.Cut(item, ENV_KEY_DELIMITER)
+			if !ok {
+				return fmt.Errorf("%q is not a KEY%sVAL pair: %w", item, ENV_KEY_DELIMITER, ErrInvalidTags)
+			}
+			keyValue := reflect.New(value.Type().Key()).Elem()
+			if err := setValue(tagValue(strings.TrimSpace(key)), keyValue); err != nil {
+				return err
+			}
+			valValue := reflect.New(value.Type().Elem()).Elem()
+			if err := setValue(tagValue(strings.TrimSpace(val)), valValue); err != nil {
+				return err
+			}
+			mapValue.SetMapIndex(keyValue, valValue)
+		}
+		value.Set(mapValue)
+
+	default:
+		return setValue(tagValue(raw), value)
+	}
+
+	return nil
+}
+
+// envName builds name of environment variable from prefix and dotted path.
+func envName(prefix, path string) string {
+	name := strings.NewReplacer(".", "_", "-", "_").Replace(path)
+	if prefix != "" {
+		name = strings.TrimSuffix(prefix, "_") + "_" + name
+	}
+	return strings.ToUpper(name)
+}
+
+func splitEnvList(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	items := strings.Split(raw, ENV_SEPARATOR)
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// setIndirect sets the value, allocating pointer if needed.
+func setIndirect(value, newValue reflect.Value) {
+	if value.Kind() != reflect.Ptr {
+		value.Set(newValue)
+		return
+	}
+	ptr := reflect.New(newValue.Type())
+	ptr.Elem().Set(newValue)
+	value.Set(ptr)
+}
@@ -0,0 +1,138 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GenerateEnvTemplate генерирует шаблон .env файла структуры с комментариями
+// из структурных тегов. Переменные названы так же, как их читает LoadEnv
+func GenerateEnvTemplate(input any, prefix string) (string, error) {
+	return new(Adapter).GenerateEnvTemplate(input, prefix)
+}
+
+// GenerateEnvTemplate генерирует шаблон так же, как одноименная функция пакета,
+// но учитывает префикс тегов, стратегию имен и пользовательские правила адаптера.
+//
+// Вложенные структуры разворачиваются по путям полей: server.port с префиксом
+// APP становится APP_SERVER_PORT. Значение переменной - текущее значение поля,
+// а если оно пустое - rst-default; переменные без значения закомментированы.
+// Как и в LoadEnv, при пустом префиксе в шаблон попадают только поля с тегом env
+func (a *Adapter) GenerateEnvTemplate(input any, prefix string) (string, error) {
+	inputValue := reflect.Indirect(reflect.ValueOf(input))
+	if inputValue.Kind() != reflect.Struct {
+		return "", ErrNotStruct
+	}
+
+	var result strings.Builder
+	result.WriteString("# Generated env template with RST tags comments\n")
+
+	a.generateEnvRecursive(inputValue, prefix, "", &result, map[reflect.Type]bool{})
+	return result.String(), nil
+}
+
+// generateEnvRecursive рекурсивно печатает переменные полей структуры,
+// отслеживая типы на пути, чтобы не зациклиться на рекурсивных типах
+func (a *Adapter) generateEnvRecursive(input reflect.Value, prefix, parentPath string, result *strings.Builder, seen map[reflect.Type]bool) {
+	seen[input.Type()] = true
+	defer delete(seen, input.Type())
+
+	// Поля после вложенного блока отделяются пустой строкой
+	afterSection := false
+
+	for _, fp := range a.planFor(input.Type()).fields {
+		field := input.Type().Field(fp.index)
+		if !field.IsExported() {
+			continue
+		}
+		name, explicit := field.Tag.Lookup(TAG_ENV)
+		if name == "-" {
+			continue
+		}
+		path := joinPath(parentPath, fp.name)
+		fieldType := indirectType(field.Type)
+		value := reflect.Indirect(input.Field(fp.index))
+
+		// Вложенные структуры печатаются отдельным блоком, если в нем есть переменные
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !explicit {
+			if seen[fieldType] {
+				continue
+			}
+			if !value.IsValid() {
+				value = reflect.New(fieldType).Elem()
+			}
+			var section strings.Builder
+			a.generateEnvRecursive(value, prefix, path, &section, seen)
+			if section.Len() == 0 {
+				continue
+			}
+			result.WriteString("\n")
+			if info := field.Tag.Get(TAG_INFO); info != "" {
+				result.WriteString(fmt.Sprintf("# %s\n", info))
+			}
+			result.WriteString(section.String())
+			afterSection = true
+			continue
+		}
+
+		if !isFlagType(fieldType) {
+			continue
+		}
+		if !explicit {
+			if prefix == "" {
+				continue
+			}
+			name = envName(prefix, path)
+		}
+
+		if afterSection {
+			result.WriteString("\n")
+			afterSection = false
+		}
+
+		comments := []string{}
+		if comment := a.generateCommentFromTags(field.Tag); comment != "" {
+			comments = append(comments, comment)
+		}
+		switch fieldType.Kind() {
+		case reflect.Slice:
+			comments = append(comments, fmt.Sprintf("значения через %q", ENV_SEPARATOR))
+		case reflect.Map:
+			comments = append(comments, fmt.Sprintf("пары KEY%sVAL через %q", ENV_KEY_DELIMITER, ENV_SEPARATOR))
+		}
+		if len(comments) > 0 {
+			result.WriteString(fmt.Sprintf("# %s\n", strings.Join(comments, "; ")))
+		}
+
+		envValue := formatFlagDefault(value)
+		if fp.rules != nil && envValue == "" && isSimpleType(reflect.New(fieldType).Elem()) {
+			if defaultTag, ok := fp.rules.tags[RST_DEFAULT]; ok {
+				envValue = string(defaultTag)
+			}
+		}
+		// Пустое значение не всегда разбирается, поэтому такие переменные закомментированы
+		if envValue == "" {
+			result.WriteString(fmt.Sprintf("# %s=\n", name))
+			continue
+		}
+		result.WriteString(fmt.Sprintf("%s=%s\n", name, quoteEnvValue(envValue)))
+	}
+}
+
+// quoteEnvValue заключает значение в кавычки, если без них
+// оно будет прочитано из .env файла иначе. Используются кавычки
+// в стиле dotenv: одинарные, а если значение само содержит
+// одинарную кавычку - двойные, в которых экранируются только
+// `"` и `\`
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n#\"'`\\$") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + envValueEscaper.Replace(value) + `"`
+}
+
+var envValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
@@ -0,0 +1,187 @@
+package adapt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateEnvTemplate(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost" info:"Хост сервера"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type Node struct {
+		Weight int   `json:"weight"`
+		Next   *Node `json:"next"`
+	}
+
+	type TestStruct struct {
+		Name    string            `json:"name" info:"Имя"`
+		Timeout time.Duration     `json:"timeout" rst-default:"1s"`
+		Server  Server            `json:"server" info:"Настройки сервера"`
+		Backup  *Server           `json:"backup"`
+		Workers []uint            `json:"workers" rst-min:"1"`
+		Limits  map[string]int    `json:"limits"`
+		Nodes   []Server          `json:"nodes"`
+		Root    Node              `json:"root"`
+		Token   string            `env:"SERVICE_TOKEN"`
+		Secret  string            `json:"secret" env:"-"`
+		Motd    string            `json:"motd"`
+		Labels  map[string]string `json:"labels"`
+	}
+
+	t.Run("Prefix", func(t *testing.T) {
+		result, err := GenerateEnvTemplate(&TestStruct{
+			Name:    "app",
+			Server:  Server{Port: 4002},
+			Workers: []uint{1, 2},
+			Limits:  map[string]int{"b": 2, "a": 1},
+			Motd:    "hello world",
+		}, "APP")
+		assert.NoError(t, err)
+		assert.Equal(t, `# Generated env template with RST tags comments
+# Имя
+APP_NAME=app
+# значение по умолчанию - 1s
+APP_TIMEOUT=1s
+
+# Настройки сервера
+# Хост сервера; значение по умолчанию - localhost
+APP_SERVER_HOST=localhost
+# минимальное значение - 4000; максимальное значение - 4010
+APP_SERVER_PORT=4002
+
+# Хост сервера; значение по умолчанию - localhost
+APP_BACKUP_HOST=localhost
+# минимальное значение - 4000; максимальное значение - 4010
+# APP_BACKUP_PORT=
+
+# минимальное значение - 1; значения через ","
+APP_WORKERS=1,2
+# пары KEY=VAL через ","
+APP_LIMITS=a=1,b=2
+
+# APP_ROOT_WEIGHT=
+
+# SERVICE_TOKEN=
+APP_MOTD='hello world'
+# пары KEY=VAL через ","
+# APP_LABELS=
+`, result)
+	})
+
+	t.Run("Round Trip", func(t *testing.T) {
+		type Config struct {
+			Name    string         `json:"name"`
+			Timeout time.Duration  `json:"timeout" rst-default:"1s"`
+			Server  Server         `json:"server"`
+			Workers []uint         `json:"workers" rst-min:"1"`
+			Limits  map[string]int `json:"limits"`
+			Token   string         `env:"SERVICE_TOKEN"`
+		}
+
+		test := Config{
+			Name:    "my app",
+			Timeout: 5 * time.Second,
+			Server:  Server{Host: "db", Port: 4005},
+			Workers: []uint{1, 2},
+			Limits:  map[string]int{"a": 1},
+			Token:   "token",
+		}
+		result, err := GenerateEnvTemplate(test, "APP")
+		assert.NoError(t, err)
+
+		for _, line := range strings.Split(result, "\n") {
+			name, value, ok := strings.Cut(line, "=")
+			if !ok || strings.HasPrefix(line, "#") {
+				continue
+			}
+			t.Setenv(name, unquoteEnvValue(value))
+		}
+
+		var loaded Config
+		assert.NoError(t, a.LoadEnv(&loaded, "APP"))
+		assert.Equal(t, test, loaded)
+	})
+
+	t.Run("Dotenv Quoting", func(t *testing.T) {
+		type Config struct {
+			Plain      string `json:"plain"`
+			Spaces     string `json:"spaces"`
+			Tab        string `json:"tab"`
+			Unicode    string `json:"unicode"`
+			Quotes     string `json:"quotes"`
+			Apostrophe string `json:"apostrophe"`
+			Path       string `json:"path"`
+		}
+
+		test := Config{
+			Plain:      "café",
+			Spaces:     "a b #c",
+			Tab:        "tab\there",
+			Unicode:    "привет мир",
+			Quotes:     `say "hi"`,
+			Apostrophe: `it's "C:\path"`,
+			Path:       `C:\path`,
+		}
+		result, err := GenerateEnvTemplate(test, "APP")
+		assert.NoError(t, err)
+		assert.Equal(t, `APP_PLAIN=café
+APP_SPACES='a b #c'
+APP_TAB='tab	here'
+APP_UNICODE='привет мир'
+APP_QUOTES='say "hi"'
+APP_APOSTROPHE="it's \"C:\\path\""
+APP_PATH='C:\path'
+`, strings.TrimPrefix(result, "# Generated env template with RST tags comments\n"))
+
+		for _, line := range strings.Split(result, "\n") {
+			name, value, ok := strings.Cut(line, "=")
+			if !ok || strings.HasPrefix(line, "#") {
+				continue
+			}
+			t.Setenv(name, unquoteEnvValue(value))
+		}
+
+		var loaded Config
+		assert.NoError(t, a.LoadEnv(&loaded, "APP"))
+		assert.Equal(t, test, loaded)
+	})
+
+	t.Run("Without Prefix", func(t *testing.T) {
+		result, err := GenerateEnvTemplate(TestStruct{Token: "secret"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "# Generated env template with RST tags comments\nSERVICE_TOKEN=secret\n", result)
+	})
+
+	t.Run("Naming", func(t *testing.T) {
+		result, err := New(WithNaming(NamingField)).GenerateEnvTemplate(Server{}, "app_")
+		assert.NoError(t, err)
+		assert.Contains(t, result, "APP_HOST=localhost\n")
+	})
+
+	t.Run("Not Struct", func(t *testing.T) {
+		_, err := GenerateEnvTemplate(1, "APP")
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
+
+// unquoteEnvValue снимает кавычки так же, как это делает
+// dotenv-парсер: одинарные без обработки, в двойных
+// раскрываются только \" и \\
+func unquoteEnvValue(value string) string {
+	if len(value) < 2 || value[0] != value[len(value)-1] {
+		return value
+	}
+	switch value[0] {
+	case '\'':
+		return value[1 : len(value)-1]
+	case '"':
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(value[1 : len(value)-1])
+	}
+	return value
+}
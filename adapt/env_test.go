@@ -0,0 +1,115 @@
+package adapt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadEnv(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type TestStruct struct {
+		Server  Server         `json:"server"`
+		Backup  *Server        `json:"backup"`
+		Token   string         `env:"SERVICE_TOKEN"`
+		Secret  string         `env:"-"`
+		Ratio   *float64       `json:"ratio" rst-max:"1"`
+		Workers []uint         `json:"workers" rst-min:"1"`
+		Limits  map[string]int `json:"limits"`
+	}
+
+	t.Run("Prefix And Path", func(t *testing.T) {
+		t.Setenv("APP_SERVER_PORT", "5000")
+		t.Setenv("APP_RATIO", "0.5")
+		t.Setenv("APP_WORKERS", "0, 2,3")
+		t.Setenv("APP_LIMITS", "a=1,b=2")
+		t.Setenv("APP_SECRET", "ignored")
+		t.Setenv("SERVICE_TOKEN", "token")
+
+		test := TestStruct{Server: Server{Host: "db"}}
+		assert.NoError(t, a.LoadEnv(&test, "APP"))
+
+		assert.Equal(t, Server{Host: "db", Port: 4010}, test.Server)
+		assert.Nil(t, test.Backup)
+		assert.Equal(t, "token", test.Token)
+		assert.Equal(t, "", test.Secret)
+		assert.Equal(t, 0.5, *test.Ratio)
+		assert.Equal(t, []uint{1, 2, 3}, test.Workers)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, test.Limits)
+	})
+
+	t.Run("Nested Pointer", func(t *testing.T) {
+		t.Setenv("APP_BACKUP_PORT", "4001")
+
+		var test TestStruct
+		assert.NoError(t, a.LoadEnv(&test, "APP_"))
+		assert.Equal(t, &Server{Host: "localhost", Port: 4001}, test.Backup)
+	})
+
+	t.Run("Only Tagged Fields Without Prefix", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "4001")
+		t.Setenv("SERVICE_TOKEN", "token")
+
+		var test TestStruct
+		assert.NoError(t, a.LoadEnv(&test, ""))
+		assert.Equal(t, 4000, test.Server.Port)
+		assert.Equal(t, "token", test.Token)
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		type TimeStruct struct {
+			Timeout time.Duration   `json:"timeout" rst-max:"1m"`
+			Start   time.Time       `json:"start"`
+			Retries []time.Duration `json:"retries"`
+		}
+		t.Setenv("APP_TIMEOUT", "1h")
+		t.Setenv("APP_START", "2024-06-01T12:00:00Z")
+		t.Setenv("APP_RETRIES", "1s, 1m30s")
+
+		var test TimeStruct
+		assert.NoError(t, a.LoadEnv(&test, "APP"))
+		assert.Equal(t, time.Minute, test.Timeout)
+		assert.True(t, test.Start.Equal(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)))
+		assert.Equal(t, []time.Duration{time.Second, 90 * time.Second}, test.Retries)
+	})
+
+	t.Run("Recursive Type", func(t *testing.T) {
+		type Node struct {
+			Value int   `json:"value"`
+			Next  *Node `json:"next"`
+		}
+		type Tree struct {
+			Root Node `json:"root"`
+		}
+		t.Setenv("APP_ROOT_VALUE", "1")
+
+		var test Tree
+		assert.NoError(t, a.LoadEnv(&test, "APP"))
+		assert.Equal(t, Tree{Root: Node{Value: 1}}, test)
+
+		_, err := NewLoader(&a, EnvSource("APP")).Load(&test)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid Value", func(t *testing.T) {
+		t.Setenv("APP_SERVER_PORT", "abc")
+
+		var test TestStruct
+		err := a.LoadEnv(&test, "APP")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "env APP_SERVER_PORT: field server.port")
+
+		t.Setenv("APP_SERVER_PORT", "4001")
+		t.Setenv("APP_LIMITS", "a")
+		assert.ErrorIs(t, a.LoadEnv(&test, "APP"), ErrInvalidTags)
+	})
+
+	t.Run("Invalid Output", func(t *testing.T) {
+		assert.ErrorIs(t, a.LoadEnv(TestStruct{}, "APP"), ErrNotStruct)
+	})
+}
@@ -0,0 +1,92 @@
+package adapt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes an error of a rule of a single field,
+// e.g. a tag value which cannot be parsed for the type of the field.
+type FieldError struct {
+	Path  string // dotted path of the field, empty for the input structure
+	Tag   string // name of the rule, or of the method for errors of AdaptFields and ValidateFields
+	Value string // value of the rule tag
+	Err   error  // underlying error; errors of parsing tags match ErrInvalidTags
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		fmt.Fprintf(&b, "field %s", e.Path)
+	}
+
+	switch {
+	case e.Tag == "":
+	case e.Tag == HOOK_ADAPT || e.Tag == HOOK_VALIDATE:
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(e.Tag)
+	default:
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "tag %s", e.Tag)
+	}
+
+	if b.Len() == 0 {
+		return e.Err.Error()
+	}
+	return b.String() + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// tagError is an error of parsing a tag value. It matches ErrInvalidTags
+// keeping the message of the parse error.
+type tagError struct {
+	err error
+}
+
+func (e tagError) Error() string {
+	return e.err.Error()
+}
+
+func (e tagError) Unwrap() []error {
+	return []error{e.err, ErrInvalidTags}
+}
+
+// FieldErrors lists every field error found in one pass
+// by Adapter with CollectErrors mode, in order of traversal.
+// errors.Is and errors.As look through each of them.
+type FieldErrors struct {
+	Errors []*FieldError
+}
+
+func (e *FieldErrors) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// NestedError returns error of the nested structure field name,
+// prefixing path of *FieldError with the name. It is used by code
+// generated with adaptgen, which reports paths relative to the structure.
+func NestedError(name string, err error) error {
+	if ferr, ok := err.(*FieldError); ok {
+		ferr.Path = joinPath(name, ferr.Path)
+	}
+	return err
+}
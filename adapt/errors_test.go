@@ -0,0 +1,111 @@
+package adapt
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FieldErrors(t *testing.T) {
+	type Server struct {
+		Port int    `json:"port" rst-min:"low" rst-max:"high"`
+		Host string `json:"host" rst-min:"1"`
+	}
+	type Config struct {
+		Server  Server   `json:"server"`
+		Servers []Server `json:"servers"`
+		Level   string   `json:"level" rst-choice:"info||debug"`
+		Ratio   float64  `json:"ratio" rst-default:"half"`
+	}
+	input := Config{Servers: []Server{{Port: 1}}, Level: "trace"}
+
+	t.Run("Collect All", func(t *testing.T) {
+		na := New(WithLogger(nil), WithErrorMode(CollectErrors))
+
+		result, err := na.AdaptStruct(input)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrInvalidTags)
+
+		var ferrs *FieldErrors
+		if assert.ErrorAs(t, err, &ferrs) {
+			got := make([][3]string, len(ferrs.Errors))
+			for i, ferr := range ferrs.Errors {
+				got[i] = [3]string{ferr.Path, ferr.Tag, ferr.Value}
+			}
+			assert.Equal(t, [][3]string{
+				{"server.port", RST_MIN, "low"},
+				{"server.port", RST_MAX, "high"},
+				{"server.host", RST_MIN, "1"},
+				{"servers.port", RST_MIN, "low"},
+				{"servers.port", RST_MAX, "high"},
+				{"servers.host", RST_MIN, "1"},
+				{"ratio", RST_DEFAULT, "half"},
+			}, got)
+		}
+
+		var ferr *FieldError
+		if assert.ErrorAs(t, err, &ferr) {
+			assert.Equal(t, "server.port", ferr.Path)
+			assert.ErrorIs(t, ferr, strconv.ErrSyntax)
+			assert.Equal(t, `field server.port, tag rst-min: strconv.Atoi: parsing "low": invalid syntax`, ferr.Error())
+		}
+
+		// Ошибки проверки тоже собираются за один проход
+		err = na.Validate(input)
+		assert.True(t, errors.As(err, &ferrs))
+		assert.Len(t, ferrs.Errors, 7)
+	})
+
+	t.Run("Fail Fast", func(t *testing.T) {
+		_, err := a.AdaptStruct(input)
+
+		var ferr *FieldError
+		if assert.ErrorAs(t, err, &ferr) {
+			assert.Equal(t, FieldError{Path: "server.port", Tag: RST_MIN, Value: "low", Err: ferr.Err}, *ferr)
+		}
+		var ferrs *FieldErrors
+		assert.False(t, errors.As(err, &ferrs))
+	})
+
+	t.Run("Parse Errors", func(t *testing.T) {
+		type Parse struct {
+			Port  int     `json:"port" rst-min:"low"`
+			Ratio float64 `json:"ratio" rst-default:"half"`
+		}
+
+		_, err := a.AdaptStruct(Parse{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+		_, err = New(WithLogger(nil), WithErrorMode(CollectErrors)).AdaptStruct(Parse{})
+		var ferrs *FieldErrors
+		if assert.ErrorAs(t, err, &ferrs) && assert.Len(t, ferrs.Errors, 2) {
+			for _, ferr := range ferrs.Errors {
+				assert.ErrorIs(t, ferr, ErrInvalidTags)
+				assert.ErrorIs(t, ferr, strconv.ErrSyntax)
+			}
+		}
+	})
+
+	t.Run("Error Text", func(t *testing.T) {
+		assert.Equal(t, "field a, tag rst-min: invalid struct tags",
+			(&FieldError{Path: "a", Tag: RST_MIN, Err: ErrInvalidTags}).Error())
+		assert.Equal(t, "field a, ValidateFields: invalid struct tags",
+			(&FieldError{Path: "a", Tag: HOOK_VALIDATE, Err: ErrInvalidTags}).Error())
+		assert.Equal(t, "AdaptFields: invalid struct tags",
+			(&FieldError{Tag: HOOK_ADAPT, Err: ErrInvalidTags}).Error())
+		assert.Equal(t, "field a: cyclic field references",
+			(&FieldError{Path: "a", Err: ErrRuleCycle}).Error())
+		assert.Equal(t, "field a: invalid struct tags\nfield b: cyclic field references",
+			(&FieldErrors{Errors: []*FieldError{{Path: "a", Err: ErrInvalidTags}, {Path: "b", Err: ErrRuleCycle}}}).Error())
+	})
+
+	t.Run("Nested Error", func(t *testing.T) {
+		err := NestedError("server", &FieldError{Tag: HOOK_VALIDATE, Err: errNoHosts})
+		err = NestedError("backup", NestedError("servers", err))
+		assert.EqualError(t, err, "field backup.servers.server, ValidateFields: no hosts")
+		assert.Equal(t, errNoHosts, NestedError("server", errNoHosts))
+	})
+}
@@ -10,12 +10,12 @@ type ExampleStruct struct {
 	Counter     int     `json:"counter" rst-min:"5" info:"Счетчик"`
 	Price       float64 `rst-max:"1000.0" info:"Цена товара"`
 	Status      string  `rst-choice:"active||inactive||pending" info:"Статус заказа"`
-	Email       string  `rst-regex:"^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$" info:"Email адрес"`
+	Email       string  `rst-regex:"match:^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$**" info:"Email адрес"`
 	UserID      uint    `rst-forbidden:"0||1||2**10" info:"ID пользователя"`
 	Description string  `rst-default:"Без описания" info:"Описание"`
 
 	// Сложные поля
-	Tags     []string               `rst-max:"10" info:"Теги"`
+	Tags     []string               `rst-maxlen:"10" info:"Теги"`
 	Settings map[string]interface{} `info:"Настройки"`
 	IsActive *bool                  `rst-default:"true" info:"Активен ли"`
 }
@@ -61,16 +61,16 @@ func RunExample() {
 
 // Пример использования с вложенной структурой
 type User struct {
-	Name   string `json:"name" rst-regex:"[a-zA-Z]+" info:"Имя пользователя"`
+	Name   string `json:"name" rst-regex:"keep:[a-zA-Z]+" info:"Имя пользователя"`
 	Age    int    `rst-min:"18" rst-max:"120" info:"Возраст"`
-	Email  string `rst-regex:"^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$" info:"Email"`
+	Email  string `rst-regex:"match:^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$**" info:"Email"`
 	Active bool   `rst-default:"true" info:"Активен ли"`
 }
 
 type Order struct {
 	ID       int      `rst-min:"1" info:"Номер заказа"`
 	User     User     `info:"Информация о пользователе"`
-	Items    []string `rst-max:"20" info:"Список товаров"`
+	Items    []string `rst-maxlen:"20" info:"Список товаров"`
 	Total    float64  `rst-min:"0.0" rst-max:"10000.0" info:"Общая сумма"`
 	Status   string   `rst-choice:"new||processing||completed||cancelled" info:"Статус заказа"`
 	Discount float64  `rst-forbidden:"0.0||-1.0**-100.0" info:"Скидка"`
@@ -125,7 +125,7 @@ func RunFileOnlyExample() {
 			Driver   string `json:"driver" rst-choice:"mysql||postgres||sqlite" info:"Тип базы данных"`
 			Host     string `json:"host" rst-default:"localhost" info:"Хост БД"`
 			Port     int    `json:"port" rst-min:"1" rst-max:"65535" rst-default:"5432" info:"Порт БД"`
-			Username string `json:"username" rst-regex:"[a-zA-Z0-9_]+" info:"Имя пользователя"`
+			Username string `json:"username" rst-regex:"keep:[a-zA-Z0-9_]+" info:"Имя пользователя"`
 			Password string `json:"password" info:"Пароль"`
 		} `json:"database" info:"Настройки базы данных"`
 
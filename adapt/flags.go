@@ -0,0 +1,204 @@
+package adapt
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const TAG_FLAG = "flag"
+
+// BindFlags defines flags of fs for fields of the structure pointed
+// to by out, as Adapter.BindFlags does with the zero Adapter.
+func BindFlags(fs *flag.FlagSet, out any) error {
+	return new(Adapter).BindFlags(fs, out)
+}
+
+// ParseFlags binds flags, parses args and adapts the structure,
+// as Adapter.ParseFlags does with the zero Adapter.
+func ParseFlags(fs *flag.FlagSet, out any, args []string) error {
+	return new(Adapter).ParseFlags(fs, out, args)
+}
+
+// BindFlags defines flags of fs for fields of the structure pointed
+// to by out. Flags are named after dotted paths of the fields, e.g.
+// -server.port, so FlagSource reads them into other structures too.
+// Usage is built from info and rst-* tags as in YAML comments,
+// default is the current value or, if it is zero, rst-default.
+//
+// Values are parsed as environment variables and written into the
+// structure when flags are set, allocating nil pointers. Rules are
+// not applied. flag:"-" excludes the field; fields of types flags
+// cannot hold, such as slices of structures, are skipped.
+func (a *Adapter) BindFlags(fs *flag.FlagSet, out any) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	return a.bindFlags(fs, outValue.Elem(), outValue.Elem(), "", map[reflect.Type]bool{})
+}
+
+// ParseFlags binds flags of the structure pointed to by out, parses
+// args and applies rules to the result.
+func (a *Adapter) ParseFlags(fs *flag.FlagSet, out any, args []string) error {
+	if err := a.BindFlags(fs, out); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	_, err := a.AdaptInPlace(out)
+	return err
+}
+
+// bindFlags defines flags for fields of the structure value,
+// which is zero for fields behind nil pointers. Types on the
+// path are tracked to stop on recursive types.
+func (a *Adapter) bindFlags(fs *flag.FlagSet, root, input reflect.Value, parentPath string, seen map[reflect.Type]bool) error {
+	seen[input.Type()] = true
+	defer delete(seen, input.Type())
+
+	for _, fp := range a.planFor(input.Type()).fields {
+		field := input.Type().Field(fp.index)
+		if !field.IsExported() || field.Tag.Get(TAG_FLAG) == "-" {
+			continue
+		}
+		path := joinPath(parentPath, fp.name)
+		fieldType := indirectType(field.Type)
+
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+			if seen[fieldType] {
+				continue
+			}
+			nested := reflect.Indirect(input.Field(fp.index))
+			if !nested.IsValid() {
+				nested = reflect.New(fieldType).Elem()
+			}
+			if err := a.bindFlags(fs, root, nested, path, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isFlagType(fieldType) {
+			continue
+		}
+		if fs.Lookup(path) != nil {
+			return fmt.Errorf("flag %s: already defined", path)
+		}
+
+		value := &fieldFlag{adapter: a, root: root, path: path, typ: fieldType}
+		value.def = formatFlagDefault(reflect.Indirect(input.Field(fp.index)))
+		if fp.rules != nil && isSimpleType(reflect.New(fieldType).Elem()) {
+			if defaultTag, ok := fp.rules.tags[RST_DEFAULT]; ok && value.def == "" {
+				value.def = string(defaultTag)
+			}
+		}
+		fs.Var(value, path, a.generateCommentFromTags(field.Tag))
+	}
+
+	return nil
+}
+
+// isFlagType reports whether flag value can be parsed into the type.
+func isFlagType(t reflect.Type) bool {
+	isSimple := func(t reflect.Type) bool {
+		return isSimpleType(reflect.New(t).Elem())
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return isSimple(t.Elem())
+	case reflect.Map:
+		return isSimple(t.Key()) && isSimple(t.Elem())
+	default:
+		return isSimple(t)
+	}
+}
+
+// fieldFlag is flag.Value writing into the field of the structure.
+type fieldFlag struct {
+	adapter *Adapter
+	root    reflect.Value
+	path    string
+	typ     reflect.Type  // type of the field without pointer
+	def     string        // default shown in usage
+	value   reflect.Value // parsed value, invalid until the flag is set
+}
+
+func (f *fieldFlag) String() string {
+	// flag.PrintDefaults вызывает String у нулевого значения
+	if f == nil {
+		return ""
+	}
+	if !f.value.IsValid() {
+		return f.def
+	}
+	return formatFlagValue(f.value)
+}
+
+func (f *fieldFlag) Set(raw string) error {
+	parsed := reflect.New(f.typ).Elem()
+	if err := setEnvValue(raw, parsed); err != nil {
+		return err
+	}
+
+	field, ok := f.adapter.fieldByPath(f.root, f.path)
+	if !ok {
+		return fmt.Errorf("field %s: not found", f.path)
+	}
+	setIndirect(field, parsed)
+	f.value = parsed
+	return nil
+}
+
+// Get returns the parsed value, so FlagSource takes it as is.
+func (f *fieldFlag) Get() any {
+	if !f.value.IsValid() {
+		return nil
+	}
+	return f.value.Interface()
+}
+
+// IsBoolFlag allows boolean flags without values, e.g. -debug.
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.typ.Kind() == reflect.Bool
+}
+
+// formatFlagDefault formats the value as formatFlagValue does,
+// returning empty string for zero values.
+func formatFlagDefault(value reflect.Value) string {
+	if !value.IsValid() || value.IsZero() {
+		return ""
+	}
+	return formatFlagValue(value)
+}
+
+// formatFlagValue formats the value in the form flags are parsed from.
+func formatFlagValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = formatFlagValue(value.Index(i))
+		}
+		return strings.Join(items, ENV_SEPARATOR)
+
+	case reflect.Map:
+		items := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			items = append(items, formatFlagValue(iter.Key())+ENV_KEY_DELIMITER+formatFlagValue(iter.Value()))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ENV_SEPARATOR)
+
+	default:
+		return formatValue(value)
+	}
+}
@@ -0,0 +1,124 @@
+package adapt
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BindFlags(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost" info:"Хост"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type Node struct {
+		Weight int   `json:"weight"`
+		Next   *Node `json:"next"`
+	}
+
+	type TestStruct struct {
+		Name    string            `json:"name"`
+		Debug   *bool             `json:"debug"`
+		Timeout time.Duration     `json:"timeout" rst-default:"1s"`
+		Server  Server            `json:"server"`
+		Backup  *Server           `json:"backup"`
+		Workers []uint            `json:"workers"`
+		Limits  map[string]int    `json:"limits"`
+		Nodes   []Server          `json:"nodes"`
+		Root    Node              `json:"root"`
+		Secret  string            `json:"secret" flag:"-"`
+		Labels  map[string]string `json:"labels"`
+	}
+
+	newFlagSet := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return fs
+	}
+
+	t.Run("Names And Usage", func(t *testing.T) {
+		fs := newFlagSet()
+		test := TestStruct{Name: "app", Workers: []uint{0, 2}}
+		require.NoError(t, BindFlags(fs, &test))
+
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, f.Name)
+		})
+		assert.Equal(t, []string{
+			"backup.host", "backup.port", "debug", "labels", "limits", "name",
+			"root.weight", "server.host", "server.port", "timeout", "workers",
+		}, names)
+
+		host := fs.Lookup("server.host")
+		assert.Equal(t, "Хост; значение по умолчанию - localhost", host.Usage)
+		assert.Equal(t, "localhost", host.DefValue)
+		assert.Equal(t, "минимальное значение - 4000; максимальное значение - 4010", fs.Lookup("server.port").Usage)
+		assert.Equal(t, "app", fs.Lookup("name").DefValue)
+		assert.Equal(t, "1s", fs.Lookup("timeout").DefValue)
+		assert.Equal(t, "0,2", fs.Lookup("workers").DefValue)
+
+		var usage bytes.Buffer
+		fs.SetOutput(&usage)
+		fs.PrintDefaults()
+		assert.Contains(t, usage.String(), "-server.host value\n    \tХост; значение по умолчанию - localhost (default localhost)\n")
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		test := TestStruct{Name: "app"}
+		err := ParseFlags(newFlagSet(), &test, []string{
+			"-debug",
+			"-server.port=5000",
+			"-backup.port", "4001",
+			"-workers=0,2",
+			"-limits=a=1,b=2",
+			"-timeout=5s",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "app", test.Name)
+		assert.True(t, *test.Debug)
+		assert.Equal(t, 5*time.Second, test.Timeout)
+		assert.Equal(t, Server{Host: "localhost", Port: 4010}, test.Server)
+		assert.Equal(t, &Server{Host: "localhost", Port: 4001}, test.Backup)
+		assert.Equal(t, []uint{0, 2}, test.Workers)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, test.Limits)
+	})
+
+	t.Run("Flag Source", func(t *testing.T) {
+		fs := newFlagSet()
+		var bound TestStruct
+		require.NoError(t, a.BindFlags(fs, &bound))
+		require.NoError(t, fs.Parse([]string{"-server.port=4005", "-limits=a=1"}))
+
+		var test TestStruct
+		origins, err := NewLoader(&a, FlagSource(fs)).Load(&test)
+		require.NoError(t, err)
+		assert.Equal(t, 4005, test.Server.Port)
+		assert.Equal(t, map[string]int{"a": 1}, test.Limits)
+		assert.Equal(t, Origins{"server.port": SOURCE_FLAGS, "limits": SOURCE_FLAGS}, origins)
+
+		assert.Equal(t, "4005", fs.Lookup("server.port").Value.String())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var test TestStruct
+		err := ParseFlags(newFlagSet(), &test, []string{"-server.port=abc"})
+		assert.ErrorContains(t, err, `invalid value "abc" for flag -server.port`)
+
+		err = ParseFlags(newFlagSet(), &test, []string{"-limits=a"})
+		assert.ErrorContains(t, err, `"a" is not a KEY=VAL pair`)
+
+		fs := newFlagSet()
+		fs.Int("name", 0, "")
+		assert.EqualError(t, BindFlags(fs, &test), "flag name: already defined")
+
+		assert.ErrorIs(t, BindFlags(newFlagSet(), test), ErrNotStruct)
+	})
+}
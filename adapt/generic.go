@@ -0,0 +1,32 @@
+package adapt
+
+import "reflect"
+
+// Adapt applies rules described in structure tags to v and returns
+// the result as a value of the same type. For structure values the
+// adapted copy is returned. For pointers to structures the value behind
+// the pointer is adapted, as AdaptStruct does, and the same pointer
+// is returned.
+func Adapt[T any](a StructAdapter, v T) (T, error) {
+	var zero T
+
+	if reflect.ValueOf(v).Kind() == reflect.Ptr {
+		if _, err := a.AdaptInPlace(v); err != nil {
+			return zero, err
+		}
+		return v, nil
+	}
+
+	adapted, err := a.AdaptStruct(v)
+	if err != nil {
+		return zero, err
+	}
+	return adapted.(T), nil
+}
+
+// AdaptPtr adapts structure pointed to by ptr in place and returns
+// changes made by rules. Unlike AdaptInPlace, the pointer is checked
+// at compile time.
+func AdaptPtr[T any](a StructAdapter, ptr *T) ([]Change, error) {
+	return a.AdaptInPlace(ptr)
+}
@@ -0,0 +1,63 @@
+package adapt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Adapt(t *testing.T) {
+	type Config struct {
+		Port int    `rst-min:"4000" rst-max:"4010"`
+		Host string `rst-default:"localhost"`
+	}
+
+	t.Run("Value", func(t *testing.T) {
+		config := Config{Port: 5000}
+
+		adapted, err := Adapt(&a, config)
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Port: 4010, Host: "localhost"}, adapted)
+		assert.Equal(t, Config{Port: 5000}, config)
+	})
+
+	t.Run("Pointer", func(t *testing.T) {
+		config := &Config{Port: 1}
+
+		adapted, err := Adapt(&a, config)
+		assert.NoError(t, err)
+		assert.Same(t, config, adapted)
+		assert.Equal(t, Config{Port: 4000, Host: "localhost"}, *config)
+	})
+
+	t.Run("Interface", func(t *testing.T) {
+		var config any = Config{Port: 1}
+
+		adapted, err := Adapt(&a, config)
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Port: 4000, Host: "localhost"}, adapted)
+	})
+
+	t.Run("AdaptPtr", func(t *testing.T) {
+		config := Config{Port: 5000, Host: "db"}
+
+		changes, err := AdaptPtr(&a, &config)
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Port: 4010, Host: "db"}, config)
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		adapted, err := Adapt(&a, 1)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		assert.Equal(t, 0, adapted)
+
+		var nilConfig *Config
+		_, err = Adapt(&a, nilConfig)
+		assert.ErrorIs(t, err, ErrNotStruct)
+
+		invalid, err := Adapt(&a, IncorrectField{IntForMax: "fail"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.Equal(t, IncorrectField{}, invalid)
+	})
+}
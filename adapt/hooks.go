@@ -0,0 +1,64 @@
+package adapt
+
+import (
+	"reflect"
+)
+
+// Adaptable is implemented by structures fixing up their fields in code
+// when tags are not enough. AdaptFields is called after rules of the
+// structure fields are applied, including rules of nested structures,
+// but before rules referencing other fields: those are applied once
+// all hooks are called and see changes made by them.
+// It is called with pointer receiver on the value being adapted and is not
+// called by Validate.
+type Adaptable interface {
+	AdaptFields() error
+}
+
+// Validatable is implemented by structures checking their fields in code.
+// When adapting, ValidateFields is called after rules of the structure
+// fields and AdaptFields are applied, and before rules referencing other
+// fields. Validate calls it too, on the values being checked: they are
+// not changed by rules, so ValidateFields sees them as they are.
+type Validatable interface {
+	ValidateFields() error
+}
+
+const (
+	HOOK_ADAPT    = "AdaptFields"
+	HOOK_VALIDATE = "ValidateFields"
+)
+
+// callHooks calls AdaptFields and ValidateFields of the structure.
+// Change made by AdaptFields is recorded as a change of the structure
+// made by rule HOOK_ADAPT.
+func (a *Adapter) callHooks(input reflect.Value, path string, st *adaptState) error {
+	target := input
+	if input.CanAddr() {
+		target = input.Addr()
+	}
+	if !target.CanInterface() {
+		return nil
+	}
+
+	if hook, ok := target.Interface().(Adaptable); ok && !st.dryRun {
+		record := a.recordsChanges(st)
+		var snap snapshot
+		if record {
+			snap = takeSnapshot(input)
+		}
+		if err := hook.AdaptFields(); err != nil {
+			return st.fail(&FieldError{Path: path, Tag: HOOK_ADAPT, Err: err})
+		}
+		if record && !snap.equal(input) {
+			a.recordChange(HOOK_ADAPT, "", snap.value(input.Type()), input, path, st)
+		}
+	}
+
+	if hook, ok := target.Interface().(Validatable); ok {
+		if err := hook.ValidateFields(); err != nil {
+			return st.fail(&FieldError{Path: path, Tag: HOOK_VALIDATE, Err: err})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package adapt
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errNoHosts = errors.New("no hosts")
+
+type hookServer struct {
+	Host  string   `json:"host" rst-default:"localhost"`
+	Port  int      `json:"port" rst-min:"1024"`
+	Hosts []string `json:"hosts"`
+	URL   string   `json:"url"`
+}
+
+// AdaptFields builds URL from fields already adapted by rules.
+func (s *hookServer) AdaptFields() error {
+	if s.URL == "" {
+		s.URL = "http://" + s.Host + ":" + strconv.Itoa(s.Port)
+	}
+	return nil
+}
+
+func (s hookServer) ValidateFields() error {
+	if len(s.Hosts) == 0 {
+		return errNoHosts
+	}
+	return nil
+}
+
+type hookConfig struct {
+	Server  hookServer            `json:"server"`
+	Backup  *hookServer           `json:"backup"`
+	Mirrors []hookServer          `json:"mirrors"`
+	ByName  map[string]hookServer `json:"by_name"`
+	Name    string                `json:"name"`
+}
+
+func (c *hookConfig) AdaptFields() error {
+	c.Name = strings.ToUpper(c.Name)
+	return nil
+}
+
+type hookBounds struct {
+	Min  int `json:"min"`
+	Max  int `json:"max" rst-min:"@min"`
+	Seen int `json:"seen"`
+}
+
+// AdaptFields records Max it sees and sets unset Min.
+func (b *hookBounds) AdaptFields() error {
+	b.Seen = b.Max
+	if b.Min == 0 {
+		b.Min = 3
+	}
+	return nil
+}
+
+func Test_Hooks(t *testing.T) {
+	valid := func() hookConfig {
+		return hookConfig{
+			Server:  hookServer{Port: 80, Hosts: []string{"a"}},
+			Backup:  &hookServer{Host: "backup", Port: 2000, Hosts: []string{"b"}},
+			Mirrors: []hookServer{{Hosts: []string{"c"}}},
+			ByName:  map[string]hookServer{"d": {Hosts: []string{"d"}, URL: "http://d"}},
+			Name:    "app",
+		}
+	}
+
+	t.Run("Adapt", func(t *testing.T) {
+		result, changes, err := a.AdaptStructWithReport(valid())
+		assert.NoError(t, err)
+
+		config := result.(hookConfig)
+		assert.Equal(t, "http://localhost:1024", config.Server.URL)
+		assert.Equal(t, "http://backup:2000", config.Backup.URL)
+		assert.Equal(t, "http://localhost:1024", config.Mirrors[0].URL)
+		assert.Equal(t, "http://d", config.ByName["d"].URL)
+		assert.Equal(t, "APP", config.Name)
+
+		// Хуки вызываются после правил полей структуры, вложенные структуры раньше внешних
+		var hooks []string
+		for _, change := range changes {
+			if change.Rule == HOOK_ADAPT {
+				hooks = append(hooks, change.Path)
+			}
+		}
+		assert.Equal(t, []string{"server", "backup", "mirrors", ""}, hooks)
+	})
+
+	t.Run("In Place", func(t *testing.T) {
+		config := valid()
+		_, err := a.AdaptInPlace(&config)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://backup:2000", config.Backup.URL)
+		assert.Equal(t, "APP", config.Name)
+	})
+
+	t.Run("Validate Errors", func(t *testing.T) {
+		config := valid()
+		config.Backup.Hosts = nil
+
+		_, err := a.AdaptStruct(config)
+		assert.ErrorIs(t, err, errNoHosts)
+		assert.EqualError(t, err, "field backup, ValidateFields: no hosts")
+
+		err = a.Validate(config)
+		assert.ErrorIs(t, err, errNoHosts)
+
+		config = valid()
+		config.Mirrors = append(config.Mirrors, hookServer{})
+		config.Server.Hosts = nil
+		_, err = New(WithLogger(nil), WithErrorMode(CollectErrors)).AdaptStruct(config)
+		assert.ErrorIs(t, err, errNoHosts)
+		assert.Contains(t, err.Error(), "field server, ValidateFields")
+		assert.Contains(t, err.Error(), "field mirrors, ValidateFields")
+	})
+
+	t.Run("Before Cross Rules", func(t *testing.T) {
+		// Правила со ссылками применяются после хуков и видят их изменения
+		result, changes, err := a.AdaptStructWithReport(hookBounds{Max: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, hookBounds{Min: 3, Max: 3, Seen: 1}, result)
+
+		rules := make([]string, len(changes))
+		for i, change := range changes {
+			rules[i] = change.Path + " " + change.Rule
+		}
+		assert.Equal(t, []string{" " + HOOK_ADAPT, "max " + RST_MIN}, rules)
+	})
+
+	t.Run("Validate Does Not Adapt", func(t *testing.T) {
+		config := valid()
+		var verr *ValidationError
+		assert.ErrorAs(t, a.Validate(&config), &verr)
+		assert.Equal(t, "", config.Server.URL)
+		assert.Equal(t, "app", config.Name)
+	})
+}
@@ -0,0 +1,337 @@
+package adapt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/amakca/go-adapt/internal/structtag"
+)
+
+const JSON_SCHEMA_DRAFT = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateJSONSchema generates JSON Schema of the structure from its
+// types and structure tags. Property names match GenerateStructYAML.
+// As in AdaptStruct, tags of slice, array and map fields describe
+// their elements. Interface fields accept any value. Recursive
+// structures are described once in $defs and referenced with $ref.
+func GenerateJSONSchema(input any) ([]byte, error) {
+	inputType := reflect.TypeOf(input)
+	if inputType == nil {
+		return nil, ErrNotStruct
+	}
+	for inputType.Kind() == reflect.Ptr {
+		inputType = inputType.Elem()
+	}
+	if inputType.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+
+	b := jsonSchemaBuilder{
+		root:      inputType,
+		walking:   make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]string),
+		defs:      make(map[string]any),
+	}
+	schema, err := b.schemaForType(inputType, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = JSON_SCHEMA_DRAFT
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// jsonSchemaBuilder builds schema of the structure, tracking structure
+// types on the current path to stop on recursive types.
+type jsonSchemaBuilder struct {
+	root      reflect.Type
+	walking   map[reflect.Type]bool
+	recursive map[reflect.Type]string // names of recursive types in $defs
+	defs      map[string]any
+}
+
+// ref returns $ref to the schema of the recursive structure type,
+// naming its definition after the type.
+func (b *jsonSchemaBuilder) ref(t reflect.Type) map[string]any {
+	if t == b.root {
+		return map[string]any{"$ref": "#"}
+	}
+	name, ok := b.recursive[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; b.defs[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		b.recursive[t] = name
+		// Место занимается сразу, определение записывается после обхода типа
+		b.defs[name] = true
+	}
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+// schemaForType recursively builds schema of the type,
+// adding keywords for structure tags of the field.
+func (b *jsonSchemaBuilder) schemaForType(t reflect.Type, tags tagsList, path string) (map[string]any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	schema := make(map[string]any)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType {
+			schema["type"] = "string"
+			schema["format"] = "date-time"
+			break
+		}
+
+		if _, ok := b.recursive[t]; ok || b.walking[t] {
+			return b.ref(t), nil
+		}
+		b.walking[t] = true
+		defer delete(b.walking, t)
+
+		schema["type"] = "object"
+
+		properties := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			// Ограничения со ссылками на другие поля не выражаются в схеме
+			tags, _ := splitCrossTags(parseStructTag(field.Tag, RST_PREFIX))
+			property, err := b.schemaForType(field.Type, tags, joinPath(path, structtag.FieldName(field.Name, field.Tag)))
+			if err != nil {
+				return nil, err
+			}
+			if info := field.Tag.Get(TAG_INFO); info != "" {
+				property["description"] = info
+			}
+			properties[yamlFieldName(field)] = property
+		}
+		schema["properties"] = properties
+
+		// Рекурсивный тип описывается в $defs, а на месте поля остается ссылка
+		if name, ok := b.recursive[t]; ok && t != b.root {
+			b.defs[name] = schema
+			return b.ref(t), nil
+		}
+		return schema, nil
+
+	case reflect.Slice, reflect.Array:
+		items, err := b.schemaForType(t.Elem(), elementTags(tags), path)
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = "array"
+		schema["items"] = items
+		if t.Kind() == reflect.Array {
+			schema["minItems"] = t.Len()
+			schema["maxItems"] = t.Len()
+		} else if err := addJSONSchemaLength(schema, tags, "minItems", "maxItems"); err != nil {
+			return nil, fmt.Errorf("field %s: %w", path, err)
+		}
+		return schema, nil
+
+	case reflect.Map:
+		values, err := b.schemaForType(t.Elem(), elementTags(tags), path)
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = "object"
+		schema["additionalProperties"] = values
+		if err := addJSONSchemaLength(schema, tags, "", "maxProperties"); err != nil {
+			return nil, fmt.Errorf("field %s: %w", path, err)
+		}
+		return schema, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+		if t == durationType {
+			// Длительности записываются в виде "1h30m"
+			schema["type"] = "string"
+		}
+
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+
+	case reflect.String:
+		schema["type"] = "string"
+
+	case reflect.Bool:
+		schema["type"] = "boolean"
+
+	case reflect.Interface:
+		// Тип значения заранее неизвестен, ограничения не описываются
+		return schema, nil
+	}
+
+	if err := addJSONSchemaKeywords(schema, t, tags); err != nil {
+		return nil, fmt.Errorf("field %s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// addJSONSchemaKeywords converts rst tags of the field into schema keywords.
+func addJSONSchemaKeywords(schema map[string]any, t reflect.Type, tagsList tagsList) error {
+	var err error
+
+	_, hasMin := tagsList[RST_MIN]
+	_, hasMax := tagsList[RST_MAX]
+	if isTimeType(t) {
+		// Границы длительностей и времени не выражаются ключевыми словами
+		// JSON Schema, поэтому только проверяются
+		for _, tn := range []tagName{RST_MIN, RST_MAX} {
+			if tv, ok := tagsList[tn]; ok {
+				if _, err := jsonSchemaLiteral(tn, tv, t); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		if (hasMin || hasMax) && schema["type"] != "integer" && schema["type"] != "number" {
+			return fmt.Errorf("tag %s/%s: %w", RST_MIN, RST_MAX, ErrInvalidTags)
+		}
+
+		if tv, ok := tagsList[RST_MIN]; ok {
+			if schema["minimum"], err = jsonSchemaLiteral(RST_MIN, tv, t); err != nil {
+				return err
+			}
+		}
+		if tv, ok := tagsList[RST_MAX]; ok {
+			if schema["maximum"], err = jsonSchemaLiteral(RST_MAX, tv, t); err != nil {
+				return err
+			}
+		}
+	}
+	if tv, ok := tagsList[RST_DEFAULT]; ok {
+		def, err := jsonSchemaLiteral(RST_DEFAULT, tv, t)
+		if err != nil {
+			return err
+		}
+		if def != nil {
+			schema["default"] = def
+		}
+	}
+	if tv, ok := tagsList[RST_CHOICE]; ok {
+		if schema["enum"], err = jsonSchemaLiterals(RST_CHOICE, strings.Split(string(tv), SET_DELIMITER), t); err != nil {
+			return err
+		}
+	}
+	if tv, ok := tagsList[RST_FORBIDDEN]; ok {
+		forbidden, _, found := strings.Cut(string(tv), VAL_DELIMITER)
+		if !found {
+			return fmt.Errorf("tag %s: %w", RST_FORBIDDEN, ErrInvalidTags)
+		}
+		enum, err := jsonSchemaLiterals(RST_FORBIDDEN, strings.Split(forbidden, SET_DELIMITER), t)
+		if err != nil {
+			return err
+		}
+		addJSONSchemaNot(schema, map[string]any{"enum": enum})
+	}
+	if hasLengthRules(tagsList) {
+		if t.Kind() != reflect.String {
+			return fmt.Errorf("tag %s/%s: %w", RST_MINLEN, RST_MAXLEN, ErrInvalidTags)
+		}
+		if err := addJSONSchemaLength(schema, tagsList, "minLength", "maxLength"); err != nil {
+			return err
+		}
+	}
+	if tv, ok := tagsList[RST_REGEX]; ok {
+		if t.Kind() != reflect.String {
+			return fmt.Errorf("tag %s: %w", RST_REGEX, ErrInvalidTags)
+		}
+		// Выражение strip описывает удаляемые символы, поэтому неизменными
+		// остаются только значения без совпадений. Режимы keep и replace
+		// переписывают значение и схемой не описываются
+		switch mode, pattern, _, _ := structtag.ParseRegex(string(tv)); mode {
+		case REGEX_MATCH:
+			schema["pattern"] = pattern
+		case REGEX_STRIP:
+			addJSONSchemaNot(schema, map[string]any{"pattern": pattern})
+		}
+	}
+
+	return nil
+}
+
+// addJSONSchemaNot adds schema the value must not match. Several such
+// schemas are combined with allOf, as not with both keywords would
+// reject only values matching all of them.
+func addJSONSchemaNot(schema map[string]any, not map[string]any) {
+	prev, ok := schema["not"]
+	if !ok {
+		schema["not"] = not
+		return
+	}
+	delete(schema, "not")
+	schema["allOf"] = []any{map[string]any{"not": prev}, map[string]any{"not": not}}
+}
+
+// addJSONSchemaLength converts length rules into keywords minKey and maxKey.
+// Empty minKey means rst-minlen is not supported for the type.
+func addJSONSchemaLength(schema map[string]any, tags tagsList, minKey, maxKey string) error {
+	if tv, ok := tags[RST_MINLEN]; ok {
+		rawLen, _, _ := strings.Cut(string(tv), VAL_DELIMITER)
+		minLen, err := parseLength(rawLen)
+		if err != nil || minKey == "" {
+			return fmt.Errorf("tag %s: %w", RST_MINLEN, ErrInvalidTags)
+		}
+		schema[minKey] = minLen
+	}
+	if tv, ok := tags[RST_MAXLEN]; ok {
+		maxLen, err := parseLength(string(tv))
+		if err != nil {
+			return fmt.Errorf("tag %s: %w", RST_MAXLEN, ErrInvalidTags)
+		}
+		schema[maxKey] = maxLen
+	}
+	return nil
+}
+
+// jsonSchemaLiteral parses tag value as a value of the field type.
+// Durations and time are returned in human form, relative
+// time has no constant value and is returned as nil.
+func jsonSchemaLiteral(name tagName, tv tagValue, t reflect.Type) (any, error) {
+	if t == timeType {
+		bound, err := parseTimeBound(string(tv))
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", name, err)
+		}
+		if bound.relative {
+			return nil, nil
+		}
+	}
+
+	value := reflect.New(t).Elem()
+	if err := setValue(tv, value); err != nil {
+		return nil, fmt.Errorf("tag %s: %w", name, err)
+	}
+	if isTimeType(t) {
+		return formatTimeValue(value), nil
+	}
+	return value.Interface(), nil
+}
+
+func jsonSchemaLiterals(name tagName, values []string, t reflect.Type) ([]any, error) {
+	literals := make([]any, 0, len(values))
+	for _, v := range values {
+		literal, err := jsonSchemaLiteral(name, tagValue(v), t)
+		if err != nil {
+			return nil, err
+		}
+		if literal != nil {
+			literals = append(literals, literal)
+		}
+	}
+	return literals, nil
+}
@@ -0,0 +1,232 @@
+package adapt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateJSONSchema(t *testing.T) {
+	t.Run("Struct With RST Tags", func(t *testing.T) {
+		type Server struct {
+			Host string `json:"host" rst-default:"localhost" info:"Хост сервера"`
+			Port uint16 `json:"port" rst-min:"4000" rst-max:"4010" rst-default:"4002" info:"Порт сервера"`
+		}
+
+		type Config struct {
+			Server   *Server           `json:"server" info:"Настройки сервера"`
+			Level    string            `json:"level" rst-choice:"debug||info"`
+			Ratios   []float64         `json:"ratios" rst-forbidden:"0||-1**0.5"`
+			Names    map[string]string `json:"names" rst-regex:"[^a-z]+"`
+			Email    string            `json:"email" rst-regex:"match:^[a-z]+@[a-z.]+$**"`
+			Pair     [2]int            `rst-min:"1"`
+			Extra    any               `rst-max:"5"`
+			internal int
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"server": {
+					"type": "object",
+					"description": "Настройки сервера",
+					"properties": {
+						"host": {"type": "string", "default": "localhost", "description": "Хост сервера"},
+						"port": {"type": "integer", "minimum": 4000, "maximum": 4010, "default": 4002, "description": "Порт сервера"}
+					}
+				},
+				"level": {"type": "string", "enum": ["debug", "info"]},
+				"ratios": {"type": "array", "items": {"type": "number", "not": {"enum": [0, -1]}}},
+				"names": {"type": "object", "additionalProperties": {"type": "string", "not": {"pattern": "[^a-z]+"}}},
+				"email": {"type": "string", "pattern": "^[a-z]+@[a-z.]+$"},
+				"pair": {"type": "array", "minItems": 2, "maxItems": 2, "items": {"type": "integer", "minimum": 1}},
+				"extra": {}
+			}
+		}`, string(schema))
+	})
+
+	t.Run("Length Rules", func(t *testing.T) {
+		type Config struct {
+			Name   string            `json:"name" rst-minlen:"3**_" rst-maxlen:"64"`
+			Hosts  []string          `json:"hosts" rst-minlen:"1" rst-maxlen:"5" rst-regex:"[ ]"`
+			Labels map[string]string `json:"labels" rst-maxlen:"10"`
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 3, "maxLength": 64},
+				"hosts": {"type": "array", "minItems": 1, "maxItems": 5, "items": {"type": "string", "not": {"pattern": "[ ]"}}},
+				"labels": {"type": "object", "maxProperties": 10, "additionalProperties": {"type": "string"}}
+			}
+		}`, string(schema))
+
+		type InvalidMapMin struct {
+			Labels map[string]string `rst-minlen:"1"`
+		}
+		_, err = GenerateJSONSchema(InvalidMapMin{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+
+		type InvalidKind struct {
+			Count int `rst-maxlen:"1"`
+		}
+		_, err = GenerateJSONSchema(InvalidKind{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+
+	t.Run("Regex Modes", func(t *testing.T) {
+		type Config struct {
+			Strip   string `json:"strip" rst-regex:"strip:[ ]"`
+			Both    string `json:"strip_forbidden" rst-regex:"[ ]" rst-forbidden:"x**y"`
+			Keep    string `json:"keep" rst-regex:"keep:[a-z]"`
+			Match   string `json:"match" rst-regex:"match:^[a-z]+$**x"`
+			Replace string `json:"replace" rst-regex:"replace:[ ]**_"`
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"strip": {"type": "string", "not": {"pattern": "[ ]"}},
+				"strip_forbidden": {"type": "string", "allOf": [{"not": {"enum": ["x"]}}, {"not": {"pattern": "[ ]"}}]},
+				"keep": {"type": "string"},
+				"match": {"type": "string", "pattern": "^[a-z]+$"},
+				"replace": {"type": "string"}
+			}
+		}`, string(schema))
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		type Config struct {
+			Enabled bool  `json:"enabled" rst-default:"true"`
+			Debug   *bool `json:"debug" rst-default:"false" rst-choice:"true||false"`
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"enabled": {"type": "boolean", "default": true},
+				"debug": {"type": "boolean", "default": false, "enum": [true, false]}
+			}
+		}`, string(schema))
+	})
+
+	t.Run("Cross Field Rules", func(t *testing.T) {
+		type Config struct {
+			MinConns int    `json:"min_conns" rst-min:"1"`
+			MaxConns int    `json:"max_conns" rst-min:"@min_conns" rst-max:"100"`
+			Key      string `json:"key" rst-required-if:"@min_conns=2" rst-default-if:"@min_conns**k"`
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"min_conns": {"type": "integer", "minimum": 1},
+				"max_conns": {"type": "integer", "maximum": 100},
+				"key": {"type": "string"}
+			}
+		}`, string(schema))
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		type Config struct {
+			Timeout time.Duration `json:"timeout" rst-min:"1s" rst-default:"1m30s" rst-choice:"1m30s||2m"`
+			Start   time.Time     `json:"start" rst-default:"2024-06-01T12:00:00+03:00"`
+			Expires time.Time     `json:"expires" rst-default:"now+24h" rst-max:"now+48h"`
+		}
+
+		schema, err := GenerateJSONSchema(Config{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"timeout": {"type": "string", "default": "1m30s", "enum": ["1m30s", "2m0s"]},
+				"start": {"type": "string", "format": "date-time", "default": "2024-06-01T12:00:00+03:00"},
+				"expires": {"type": "string", "format": "date-time"}
+			}
+		}`, string(schema))
+
+		type InvalidDuration struct {
+			Timeout time.Duration `rst-max:"10"`
+		}
+		_, err = GenerateJSONSchema(InvalidDuration{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Recursive Types", func(t *testing.T) {
+		type Node struct {
+			Value int   `json:"value" rst-min:"1"`
+			Next  *Node `json:"next"`
+		}
+		type Tree struct {
+			Name     string `json:"name"`
+			Children []Tree `json:"children"`
+			Head     *Node  `json:"head"`
+			Tail     *Node  `json:"tail" info:"Last node"`
+		}
+
+		schema, err := GenerateJSONSchema(Tree{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"children": {"type": "array", "items": {"$ref": "#"}},
+				"head": {"$ref": "#/$defs/Node"},
+				"tail": {"$ref": "#/$defs/Node", "description": "Last node"}
+			},
+			"$defs": {
+				"Node": {
+					"type": "object",
+					"properties": {
+						"value": {"type": "integer", "minimum": 1},
+						"next": {"$ref": "#/$defs/Node"}
+					}
+				}
+			}
+		}`, string(schema))
+	})
+
+	t.Run("Invalid Tags", func(t *testing.T) {
+		type InvalidMin struct {
+			Count int `rst-min:"abc"`
+		}
+		_, err := GenerateJSONSchema(InvalidMin{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "field Count: tag rst-min")
+
+		_, err = GenerateJSONSchema(IncorrectField{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+
+		type InvalidForbidden struct {
+			Count int `rst-forbidden:"1||2"`
+		}
+		_, err = GenerateJSONSchema(InvalidForbidden{})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+
+	t.Run("Not Struct", func(t *testing.T) {
+		_, err := GenerateJSONSchema(1)
+		assert.ErrorIs(t, err, ErrNotStruct)
+
+		_, err = GenerateJSONSchema(nil)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
@@ -0,0 +1,434 @@
+package adapt
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of built-in sources in Origins.
+const (
+	SOURCE_DEFAULT  = "default"
+	SOURCE_ENV      = "env"
+	SOURCE_FLAGS    = "flags"
+	SOURCE_OVERRIDE = "override"
+)
+
+// Source supplies values of structure fields to Loader.
+type Source interface {
+	// Name identifies the source in Origins.
+	Name() string
+
+	// Load writes values of the source into the structure pointed to by out
+	// without applying rules and returns the fields it set in order.
+	// Structures are set field by field, other values as a whole.
+	Load(a *Adapter, out any) ([]SourceValue, error)
+}
+
+// SourceValue describes a field set by a source.
+type SourceValue struct {
+	Path string // dotted path of the field
+	Raw  string // value as written in the source
+}
+
+// Origins maps dotted paths of fields to names of sources
+// which supplied their final values.
+type Origins map[string]string
+
+// Loader assembles structure from ordered sources: every source
+// overrides fields set by the previous ones, fields it does not set
+// keep their values. Rules of structure tags are applied once,
+// after all sources are loaded.
+type Loader struct {
+	adapter *Adapter
+	sources []Source
+}
+
+// NewLoader creates Loader of the sources in order of precedence,
+// from the lowest to the highest. Nil adapter is replaced with
+// the zero Adapter.
+func NewLoader(a *Adapter, sources ...Source) *Loader {
+	if a == nil {
+		a = &Adapter{}
+	}
+	return &Loader{adapter: a, sources: sources}
+}
+
+// Load loads sources into the structure pointed to by out, applies
+// rules to the result and returns sources of values of the fields.
+// Fields set by no source are not listed.
+func (l *Loader) Load(out any) (Origins, error) {
+	values, _, err := l.load(out)
+	if values == nil {
+		return nil, err
+	}
+
+	origins := make(Origins, len(values))
+	for path, v := range values {
+		origins[path] = v.source
+	}
+	return origins, err
+}
+
+// origin is the source which set the field and the value it read.
+type origin struct {
+	source string
+	raw    string
+}
+
+// load loads sources into the structure, applies rules and returns
+// origins of the fields with changes made by rules. Origins are nil
+// if sources could not be loaded.
+func (l *Loader) load(out any) (map[string]origin, []Change, error) {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Struct {
+		return nil, nil, ErrNotStruct
+	}
+
+	origins := make(map[string]origin)
+	for _, src := range l.sources {
+		values, err := src.Load(l.adapter, out)
+		if err != nil {
+			return nil, nil, fmt.Errorf("source %s: %w", src.Name(), err)
+		}
+		for _, v := range values {
+			setOrigin(origins, v.Path, origin{source: src.Name(), raw: v.Raw})
+		}
+	}
+
+	changes, err := l.adapter.AdaptInPlace(out)
+	return origins, changes, err
+}
+
+// setOrigin records origin of the path. Values of nested fields
+// set before belong to the new value of the path.
+func setOrigin(origins map[string]origin, path string, o origin) {
+	if path == "" {
+		return
+	}
+	for p := range origins {
+		if strings.HasPrefix(p, path+".") {
+			delete(origins, p)
+		}
+	}
+	origins[path] = o
+}
+
+// DefaultsSource sets zero fields with rst-default to their defaults.
+// Fields with rst-default-if are left to the rules, nil pointers
+// to structures are left nil.
+func DefaultsSource() Source {
+	return sourceFunc{name: SOURCE_DEFAULT, load: func(a *Adapter, out reflect.Value, mark func(path, raw string)) error {
+		return a.loadDefaults(out, "", mark)
+	}}
+}
+
+// FileSource reads YAML file, or JSON file as a subset of YAML,
+// as DecodeYAML does. The source is named after the file.
+func FileSource(filename string) Source {
+	return fileSource(filename)
+}
+
+// fileSource is a distinct type so that Watcher can find files to poll.
+type fileSource string
+
+func (s fileSource) Name() string {
+	return string(s)
+}
+
+func (s fileSource) Load(a *Adapter, out any) ([]SourceValue, error) {
+	return sourceFunc{name: string(s), load: func(a *Adapter, out reflect.Value, mark func(path, raw string)) error {
+		file, err := os.Open(string(s))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return a.decodeYAML(file, out, mark)
+	}}.Load(a, out)
+}
+
+// EnvSource reads environment variables as LoadEnv does.
+func EnvSource(prefix string) Source {
+	return sourceFunc{name: SOURCE_ENV, load: func(a *Adapter, out reflect.Value, mark func(path, raw string)) error {
+		_, err := a.loadEnvFields(out, prefix, "", mark, map[reflect.Type]bool{})
+		return err
+	}}
+}
+
+// FlagSource reads flags set on the command line, named after
+// dotted paths of the fields, e.g. -server.port. Flags not matching
+// any field are ignored. Values are parsed as environment variables.
+func FlagSource(fs *flag.FlagSet) Source {
+	return sourceFunc{name: SOURCE_FLAGS, load: func(a *Adapter, out reflect.Value, mark func(path, raw string)) error {
+		var err error
+		fs.Visit(func(f *flag.Flag) {
+			field, ok := a.fieldByPath(out, f.Name)
+			if !ok || err != nil {
+				return
+			}
+			if err = setFlagValue(f, field); err != nil {
+				err = fmt.Errorf("flag %s: %w", f.Name, err)
+				return
+			}
+			mark(f.Name, f.Value.String())
+		})
+		return err
+	}}
+}
+
+// OverrideSource sets fields by dotted paths in sorted order, so
+// a structure is set before its fields. Values are converted to
+// types of the fields, strings are parsed as environment variables.
+// Paths not matching any field are errors.
+func OverrideSource(values map[string]any) Source {
+	return sourceFunc{name: SOURCE_OVERRIDE, load: func(a *Adapter, out reflect.Value, mark func(path, raw string)) error {
+		// Пути применяются по порядку, чтобы поле было задано позже
+		// своей структуры независимо от порядка обхода карты
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			v := values[path]
+			field, ok := a.fieldByPath(out, path)
+			if !ok {
+				return fmt.Errorf("field %s: not found", path)
+			}
+			if err := setAnyValue(v, field); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+			mark(path, fmt.Sprint(v))
+		}
+		return nil
+	}}
+}
+
+// sourceFunc implements Source with a function writing into the structure.
+type sourceFunc struct {
+	name string
+	load func(a *Adapter, out reflect.Value, mark func(path, raw string)) error
+}
+
+func (s sourceFunc) Name() string {
+	return s.name
+}
+
+func (s sourceFunc) Load(a *Adapter, out any) ([]SourceValue, error) {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+
+	var values []SourceValue
+	err := s.load(a, outValue.Elem(), func(path, raw string) {
+		values = append(values, SourceValue{Path: path, Raw: raw})
+	})
+	return values, err
+}
+
+// loadDefaults sets zero fields of the structure to rst-default,
+// calling mark with their paths and defaults.
+func (a *Adapter) loadDefaults(input reflect.Value, parentPath string, mark func(path, raw string)) error {
+	plan := a.planFor(input.Type())
+
+	for _, fp := range plan.fields {
+		field := input.Type().Field(fp.index)
+		if !field.IsExported() {
+			continue
+		}
+		path := joinPath(parentPath, fp.name)
+		value := input.Field(fp.index)
+
+		// Как и AdaptInPlace, nil указатели на структуры не создаются
+		nested := reflect.Indirect(value)
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			if err := a.loadDefaults(nested, path, mark); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fp.rules == nil || fp.rules.hasDefaultIf() || !isSimpleType(reflect.New(fieldType).Elem()) {
+			continue
+		}
+		defaultTag, ok := fp.rules.tags[RST_DEFAULT]
+		if !ok || isSetBool(value) || nested.IsValid() && !nested.IsZero() {
+			continue
+		}
+
+		parsed := reflect.New(fieldType).Elem()
+		if err := setValue(defaultTag, parsed); err != nil {
+			return &FieldError{Path: path, Tag: RST_DEFAULT, Value: string(defaultTag), Err: err}
+		}
+		setIndirect(value, parsed)
+		mark(path, string(defaultTag))
+	}
+
+	return nil
+}
+
+// fieldByPath returns field of the structure by its dotted path,
+// allocating nil pointers to structures on the way. The path is
+// resolved by types first, so unknown paths leave the structure as is.
+func (a *Adapter) fieldByPath(root reflect.Value, path string) (reflect.Value, bool) {
+	names := strings.Split(path, ".")
+	indexes := make([]int, len(names))
+	t := root.Type()
+	for i, name := range names {
+		t = derefType(t)
+		if t.Kind() != reflect.Struct || t == timeType {
+			return reflect.Value{}, false
+		}
+
+		found := false
+		for _, fp := range a.planFor(t).fields {
+			if fp.name == name && t.Field(fp.index).IsExported() {
+				indexes[i], found = fp.index, true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+		t = t.Field(indexes[i]).Type
+	}
+
+	value := root
+	for _, index := range indexes {
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(index)
+	}
+	return value, true
+}
+
+// setFlagValue sets the field from the flag, using value of flag.Getter
+// if it fits the field.
+func setFlagValue(f *flag.Flag, field reflect.Value) error {
+	if getter, ok := f.Value.(flag.Getter); ok {
+		if v := reflect.ValueOf(getter.Get()); v.IsValid() && v.Type().AssignableTo(indirectType(field.Type())) {
+			setIndirect(field, v)
+			return nil
+		}
+	}
+	return setParsedValue(f.Value.String(), field)
+}
+
+// setAnyValue sets the field to the value of the same or convertible
+// numeric type, or parses the string. Numbers are converted only if
+// the field holds them exactly.
+func setAnyValue(v any, field reflect.Value) error {
+	if v == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	t := indirectType(field.Type())
+	switch {
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Type().AssignableTo(t):
+		setIndirect(field, rv)
+	case isNumberKind(rv.Kind()) && isNumberKind(t.Kind()):
+		converted, err := convertNumber(rv, t)
+		if err != nil {
+			return err
+		}
+		setIndirect(field, converted)
+	case rv.Kind() == reflect.String:
+		return setParsedValue(rv.String(), field)
+	default:
+		return fmt.Errorf("%T is not assignable to %s: %w", v, field.Type(), ErrInvalidTags)
+	}
+	return nil
+}
+
+// convertNumber converts the number to the numeric type t, rejecting
+// values out of its range and fractions for integer types.
+func convertNumber(rv reflect.Value, t reflect.Type) (reflect.Value, error) {
+	result := reflect.New(t).Elem()
+	overflow := false
+
+	switch {
+	case rv.CanInt():
+		n := rv.Int()
+		switch {
+		case result.CanInt():
+			overflow = result.OverflowInt(n)
+		case result.CanUint():
+			overflow = n < 0 || result.OverflowUint(uint64(n))
+		}
+
+	case rv.CanUint():
+		n := rv.Uint()
+		if result.CanInt() {
+			overflow = n > math.MaxInt64 || result.OverflowInt(int64(n))
+		} else if result.CanUint() {
+			overflow = result.OverflowUint(n)
+		}
+
+	default:
+		f := rv.Float()
+		if !result.CanFloat() && f != math.Trunc(f) {
+			return reflect.Value{}, fmt.Errorf("%v is not an integer: %w", rv, strconv.ErrSyntax)
+		}
+		// Границы сравниваются до преобразования, которое для них не определено
+		switch {
+		case result.CanInt():
+			overflow = f < math.MinInt64 || f >= math.MaxInt64 || result.OverflowInt(int64(f))
+		case result.CanUint():
+			overflow = f < 0 || f >= math.MaxUint64 || result.OverflowUint(uint64(f))
+		default:
+			overflow = result.OverflowFloat(f)
+		}
+	}
+
+	if overflow {
+		return reflect.Value{}, fmt.Errorf("%v overflows %s: %w", rv, t, strconv.ErrRange)
+	}
+	return rv.Convert(t), nil
+}
+
+// setParsedValue parses the string as environment variable into the field.
+func setParsedValue(raw string, field reflect.Value) error {
+	parsed := reflect.New(indirectType(field.Type())).Elem()
+	if err := setEnvValue(raw, parsed); err != nil {
+		return err
+	}
+	setIndirect(field, parsed)
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
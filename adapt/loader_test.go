@@ -0,0 +1,197 @@
+package adapt
+
+import (
+	"errors"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Loader(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost"`
+		Port int    `json:"port" rst-default:"8080" rst-max:"9000"`
+	}
+
+	type TestStruct struct {
+		Name    string        `json:"name" rst-default:"app"`
+		Debug   *bool         `json:"debug" rst-default:"true"`
+		Timeout time.Duration `json:"timeout" rst-default:"1s"`
+		Server  Server        `json:"server"`
+		Backup  *Server       `json:"backup"`
+		Tags    []string      `json:"tags"`
+	}
+
+	writeFile := func(t *testing.T, content string) string {
+		filename := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+		return filename
+	}
+
+	t.Run("Precedence", func(t *testing.T) {
+		filename := writeFile(t, "name: file\nserver:\n  host: db\n  port: 5000\ntags: [a, b]\n")
+		t.Setenv("APP_SERVER_PORT", "6000")
+		t.Setenv("APP_TIMEOUT", "5s")
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Int("server.port", 0, "")
+		fs.String("unknown", "", "")
+		require.NoError(t, fs.Parse([]string{"-server.port=7000", "-unknown=x"}))
+
+		var test TestStruct
+		loader := NewLoader(&a,
+			DefaultsSource(),
+			FileSource(filename),
+			EnvSource("APP"),
+			FlagSource(fs),
+			OverrideSource(map[string]any{"timeout": 3 * time.Second}),
+		)
+		origins, err := loader.Load(&test)
+		require.NoError(t, err)
+
+		assert.Equal(t, "file", test.Name)
+		assert.True(t, *test.Debug)
+		assert.Equal(t, 3*time.Second, test.Timeout)
+		assert.Equal(t, Server{Host: "db", Port: 7000}, test.Server)
+		assert.Nil(t, test.Backup)
+		assert.Equal(t, []string{"a", "b"}, test.Tags)
+
+		assert.Equal(t, Origins{
+			"name":        filename,
+			"debug":       SOURCE_DEFAULT,
+			"timeout":     SOURCE_OVERRIDE,
+			"server.host": filename,
+			"server.port": SOURCE_FLAGS,
+			"tags":        filename,
+		}, origins)
+	})
+
+	t.Run("Rules Applied Once", func(t *testing.T) {
+		var test TestStruct
+		origins, err := NewLoader(&a, OverrideSource(map[string]any{
+			"server.port": "10000",
+			"backup.host": "replica",
+		})).Load(&test)
+		require.NoError(t, err)
+
+		assert.Equal(t, Server{Host: "localhost", Port: 9000}, test.Server)
+		assert.Equal(t, &Server{Host: "replica", Port: 8080}, test.Backup)
+		assert.Equal(t, Origins{"server.port": SOURCE_OVERRIDE, "backup.host": SOURCE_OVERRIDE}, origins)
+	})
+
+	t.Run("Defaults Keep Set Values", func(t *testing.T) {
+		test := TestStruct{Name: "set", Debug: new(bool)}
+		origins, err := NewLoader(nil, DefaultsSource()).Load(&test)
+		require.NoError(t, err)
+
+		assert.Equal(t, "set", test.Name)
+		assert.False(t, *test.Debug)
+		assert.Nil(t, test.Backup)
+		assert.Equal(t, Origins{"timeout": SOURCE_DEFAULT, "server.host": SOURCE_DEFAULT, "server.port": SOURCE_DEFAULT}, origins)
+	})
+
+	t.Run("Whole Value Replaces Nested Origins", func(t *testing.T) {
+		filename := writeFile(t, "backup: null\n")
+
+		var test TestStruct
+		origins, err := NewLoader(&a,
+			OverrideSource(map[string]any{"backup.port": 80}),
+			FileSource(filename),
+		).Load(&test)
+		require.NoError(t, err)
+
+		assert.Nil(t, test.Backup)
+		assert.Equal(t, Origins{"backup": filename}, origins)
+	})
+
+	t.Run("Override Order", func(t *testing.T) {
+		// Карта обходится в случайном порядке, поэтому проверка повторяется
+		for i := 0; i < 20; i++ {
+			var test TestStruct
+			origins, err := NewLoader(nil, OverrideSource(map[string]any{
+				"server.port": 7000,
+				"server":      Server{Host: "db", Port: 1},
+			})).Load(&test)
+			require.NoError(t, err)
+			assert.Equal(t, Server{Host: "db", Port: 7000}, test.Server)
+			assert.Equal(t, Origins{"server": SOURCE_OVERRIDE, "server.port": SOURCE_OVERRIDE}, origins)
+		}
+	})
+
+	t.Run("Unknown Path Keeps Pointers", func(t *testing.T) {
+		var test TestStruct
+		_, err := NewLoader(nil, OverrideSource(map[string]any{"backup.unknown": 1})).Load(&test)
+		assert.EqualError(t, err, "source override: field backup.unknown: not found")
+		assert.Nil(t, test.Backup)
+	})
+
+	t.Run("Number Conversion", func(t *testing.T) {
+		type Numbers struct {
+			Small int16   `json:"small"`
+			Count uint    `json:"count"`
+			Size  int     `json:"size"`
+			Ratio float32 `json:"ratio"`
+		}
+
+		var test Numbers
+		_, err := NewLoader(nil, OverrideSource(map[string]any{
+			"small": int64(-32768),
+			"count": 42,
+			"size":  3.0,
+			"ratio": 0.5,
+		})).Load(&test)
+		require.NoError(t, err)
+		assert.Equal(t, Numbers{Small: -32768, Count: 42, Size: 3, Ratio: 0.5}, test)
+
+		for _, tc := range []struct {
+			path  string
+			value any
+			err   error
+			msg   string
+		}{
+			{"small", 70000, strconv.ErrRange, "field small: 70000 overflows int16"},
+			{"count", -1, strconv.ErrRange, "field count: -1 overflows uint"},
+			{"count", -0.5, strconv.ErrSyntax, "field count: -0.5 is not an integer"},
+			{"size", 3.9, strconv.ErrSyntax, "field size: 3.9 is not an integer"},
+			{"size", 1e30, strconv.ErrRange, "field size: 1e+30 overflows int"},
+			{"size", uint64(math.MaxUint64), strconv.ErrRange, "field size: 18446744073709551615 overflows int"},
+			{"ratio", 1e300, strconv.ErrRange, "field ratio: 1e+300 overflows float32"},
+		} {
+			var test Numbers
+			_, err := NewLoader(nil, OverrideSource(map[string]any{tc.path: tc.value})).Load(&test)
+			assert.ErrorIs(t, err, tc.err, tc.path)
+			assert.ErrorContains(t, err, tc.msg)
+			assert.Zero(t, test)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var test TestStruct
+
+		_, err := NewLoader(&a, FileSource("missing.yaml")).Load(&test)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.ErrorContains(t, err, "source missing.yaml")
+
+		_, err = NewLoader(&a, OverrideSource(map[string]any{"server.unknown": 1})).Load(&test)
+		assert.EqualError(t, err, "source override: field server.unknown: not found")
+
+		_, err = NewLoader(&a, OverrideSource(map[string]any{"tags": 1})).Load(&test)
+		assert.ErrorIs(t, err, ErrInvalidTags)
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("server.port", "", "")
+		require.NoError(t, fs.Parse([]string{"-server.port=abc"}))
+		_, err = NewLoader(&a, FlagSource(fs)).Load(&test)
+		assert.ErrorContains(t, err, "source flags: flag server.port")
+
+		_, err = NewLoader(&a).Load(test)
+		assert.True(t, errors.Is(err, ErrNotStruct))
+	})
+}
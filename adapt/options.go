@@ -0,0 +1,111 @@
+package adapt
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/amakca/go-adapt/internal/structtag"
+)
+
+// Option configures Adapter created by New.
+type Option func(*Adapter)
+
+// ErrorMode defines how Adapter handles invalid tags.
+type ErrorMode int
+
+const (
+	// FailFast stops on the first invalid tag, returning *FieldError.
+	FailFast ErrorMode = iota
+	// CollectErrors keeps processing other fields and returns
+	// all errors found in one pass as *FieldErrors.
+	CollectErrors
+)
+
+// NamingStrategy defines how fields are named in dotted paths
+// used in errors, reports and names of environment variables.
+type NamingStrategy int
+
+const (
+	// NamingJSON uses name from json tag, falling back to the field name.
+	NamingJSON NamingStrategy = iota
+	// NamingField uses name of the field.
+	NamingField
+	// NamingYAML uses keys of GenerateStructYAML: name from json tag,
+	// falling back to the field name in lower case.
+	NamingYAML
+)
+
+func (n NamingStrategy) fieldName(field reflect.StructField) string {
+	switch n {
+	case NamingField:
+		return field.Name
+	case NamingYAML:
+		return yamlFieldName(field)
+	default:
+		return structtag.FieldName(field.Name, field.Tag)
+	}
+}
+
+// StructAdapter is the set of methods Adapter provides
+// for adapting structures.
+type StructAdapter interface {
+	AdaptStruct(input any) (any, error)
+	AdaptStructWithReport(input any) (any, []Change, error)
+	AdaptInPlace(ptr any) ([]Change, error)
+	Validate(input any) error
+}
+
+var _ StructAdapter = (*Adapter)(nil)
+
+// WithLogger sets logger of changes made by rules, nil disables logging.
+func WithLogger(logger *log.Logger) Option {
+	return func(a *Adapter) {
+		a.logger = logger
+	}
+}
+
+// WithRule registers a user-defined rule as RegisterRule does.
+func WithRule(name string, fn RuleFunc) Option {
+	return func(a *Adapter) {
+		a.rules = append(a.rules, customRule{name: tagName(name), apply: fn})
+	}
+}
+
+// WithRuleComment registers a user-defined rule with the function
+// describing it in YAML comments.
+func WithRuleComment(name string, fn RuleFunc, comment CommentFunc) Option {
+	return func(a *Adapter) {
+		a.rules = append(a.rules, customRule{name: tagName(name), apply: fn, comment: comment})
+	}
+}
+
+// WithTagPrefix replaces "rst-" in names of the tags read by Adapter,
+// e.g. with prefix "cfg-" rst-min is read from cfg-min.
+// Built-in rules are still reported under their canonical names,
+// names of custom rules must start with the prefix.
+func WithTagPrefix(prefix string) Option {
+	return func(a *Adapter) {
+		a.prefix = prefix
+	}
+}
+
+// WithErrorMode sets how invalid tags are handled, FailFast by default.
+func WithErrorMode(mode ErrorMode) Option {
+	return func(a *Adapter) {
+		a.errorMode = mode
+	}
+}
+
+// WithNaming sets how fields are named in dotted paths, NamingJSON by default.
+func WithNaming(naming NamingStrategy) Option {
+	return func(a *Adapter) {
+		a.naming = naming
+	}
+}
+
+func (a *Adapter) tagPrefix() string {
+	if a.prefix == "" {
+		return RST_PREFIX
+	}
+	return a.prefix
+}
@@ -0,0 +1,212 @@
+package adapt
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Run("Logger", func(t *testing.T) {
+		type Config struct {
+			Port int `rst-min:"1024"`
+		}
+		var buf bytes.Buffer
+		na := New(WithLogger(log.New(&buf, "", 0)))
+
+		_, err := na.AdaptStruct(Config{Port: 80})
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `field="Port"`)
+
+		na = New(WithLogger(nil))
+		assert.Nil(t, na.logger)
+		_, err = na.AdaptStruct(Config{Port: 80})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rules", func(t *testing.T) {
+		type Config struct {
+			Name string `rst-lower:"true" rst-suffix:"!"`
+		}
+		lower := func(_ string, value reflect.Value, _ string) error {
+			value.SetString(strings.ToLower(value.String()))
+			return nil
+		}
+		suffix := func(tv string, value reflect.Value, _ string) error {
+			value.SetString(value.String() + tv)
+			return nil
+		}
+		na := New(
+			WithLogger(nil),
+			WithRule("rst-lower", lower),
+			WithRuleComment("rst-suffix", suffix, func(tv string) string { return "suffix " + tv }),
+		)
+
+		result, err := na.AdaptStruct(Config{Name: "ABC"})
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Name: "abc!"}, result)
+
+		yaml, err := na.GenerateStructYAML(Config{})
+		assert.NoError(t, err)
+		assert.Contains(t, yaml, "suffix !")
+	})
+
+	t.Run("Invalid Rules", func(t *testing.T) {
+		noop := func(string, reflect.Value, string) error { return nil }
+
+		assert.Panics(t, func() { New(WithRule("lower", noop)) })
+		assert.Panics(t, func() { New(WithRule("rst-min", noop)) })
+		assert.Panics(t, func() { New(WithRule("rst-required-if", noop)) })
+		assert.Panics(t, func() { New(WithRule("rst-lower", nil)) })
+		assert.Panics(t, func() { New(WithRule("rst-lower", noop), WithRule("rst-lower", noop)) })
+		assert.Panics(t, func() { New(WithRule("rst-lower", noop), WithTagPrefix("cfg-")) })
+		assert.NotPanics(t, func() { New(WithRule("cfg-lower", noop), WithTagPrefix("cfg-")) })
+	})
+
+	t.Run("Invalid Rules Error", func(t *testing.T) {
+		noop := func(string, reflect.Value, string) error { return nil }
+
+		na, err := NewE(WithRule("lower", noop))
+		assert.Nil(t, na)
+		assert.ErrorIs(t, err, ErrInvalidRule)
+
+		_, err = NewE(WithRule("rst-lower", noop), WithRule("rst-lower", noop))
+		assert.ErrorIs(t, err, ErrRuleExists)
+
+		na, err = NewE(WithLogger(nil), WithRule("rst-lower", noop))
+		assert.NoError(t, err)
+		assert.Len(t, na.rules, 1)
+	})
+}
+
+func Test_WithTagPrefix(t *testing.T) {
+	type Config struct {
+		Port  int    `json:"port" cfg-min:"1024" rst-max:"10"`
+		Host  string `cfg-default:"localhost" info:"host"`
+		Level string `cfg-choice:"info||debug"`
+	}
+	na := New(WithLogger(nil), WithTagPrefix("cfg-"))
+
+	result, changes, err := na.AdaptStructWithReport(Config{Port: 80, Level: "trace"})
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Port: 1024, Host: "localhost", Level: "info"}, result)
+	rules := make([]string, len(changes))
+	for i, change := range changes {
+		rules[i] = change.Rule
+	}
+	assert.Equal(t, []string{RST_MIN, RST_DEFAULT, RST_CHOICE}, rules)
+
+	// Адаптер по умолчанию не читает теги с другим префиксом
+	result, err = a.AdaptStruct(Config{Port: 80, Level: "trace"})
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Port: 10, Level: "trace"}, result)
+
+	yaml, err := na.GenerateStructYAML(Config{})
+	assert.NoError(t, err)
+	assert.Contains(t, yaml, "минимальное значение - 1024")
+	assert.NotContains(t, yaml, "максимальное значение")
+}
+
+func Test_WithErrorMode(t *testing.T) {
+	type Config struct {
+		Min     int    `rst-min:"1" rst-choice:"1||abc"`
+		Port    int    `rst-max:"100"`
+		Default string `rst-default:"x" rst-min:"1"`
+	}
+	input := Config{Port: 200}
+
+	t.Run("Fail Fast", func(t *testing.T) {
+		_, err := New(WithLogger(nil)).AdaptStruct(input)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "field Min")
+		assert.NotContains(t, err.Error(), "field Default")
+	})
+
+	t.Run("Collect Errors", func(t *testing.T) {
+		na := New(WithLogger(nil), WithErrorMode(CollectErrors))
+
+		_, err := na.AdaptStruct(input)
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		assert.Contains(t, err.Error(), "field Min")
+		assert.Contains(t, err.Error(), "field Default")
+
+		ptr := input
+		changes, err := na.AdaptInPlace(&ptr)
+		assert.Error(t, err)
+		assert.Equal(t, 100, ptr.Port)
+		assert.NotEmpty(t, changes)
+
+		err = na.Validate(input)
+		assert.ErrorIs(t, err, ErrInvalidTags)
+		var verr *ValidationError
+		assert.False(t, errors.As(err, &verr))
+	})
+}
+
+func Test_WithNaming(t *testing.T) {
+	type Server struct {
+		Port int `json:"port" rst-min:"1024"`
+	}
+	type Config struct {
+		HTTPServer Server
+	}
+
+	tests := []struct {
+		name   string
+		naming NamingStrategy
+		path   string
+	}{
+		{"JSON", NamingJSON, "HTTPServer.port"},
+		{"Field", NamingField, "HTTPServer.Port"},
+		{"YAML", NamingYAML, "httpserver.port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			na := New(WithLogger(nil), WithNaming(tt.naming))
+
+			_, changes, err := na.AdaptStructWithReport(Config{})
+			assert.NoError(t, err)
+			if assert.Len(t, changes, 1) {
+				assert.Equal(t, tt.path, changes[0].Path)
+			}
+		})
+	}
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("APP_HTTPSERVER_PORT", "2000")
+		var config Config
+
+		err := New(WithLogger(nil), WithNaming(NamingField)).LoadEnv(&config, "APP")
+		assert.NoError(t, err)
+		assert.Equal(t, 2000, config.HTTPServer.Port)
+	})
+}
+
+type fakeAdapter struct {
+	StructAdapter
+	calls int
+}
+
+func (f *fakeAdapter) AdaptInPlace(ptr any) ([]Change, error) {
+	f.calls++
+	return nil, nil
+}
+
+func Test_StructAdapter(t *testing.T) {
+	type Config struct {
+		Port int `rst-min:"1024"`
+	}
+	fake := &fakeAdapter{}
+	config := &Config{Port: 80}
+
+	_, err := AdaptPtr(fake, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fake.calls)
+	assert.Equal(t, 80, config.Port)
+}
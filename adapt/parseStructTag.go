@@ -2,32 +2,28 @@ package adapt
 
 import (
 	"reflect"
+	"strings"
 )
 
 // parseStructTags parse structural tag, forming a map
 // consisting of sets of tag name and its value.
-func parseStructTag(tag reflect.StructTag) tagsList {
+// Tags are looked up with the prefix, but stored under canonical names.
+func parseStructTag(tag reflect.StructTag, prefix string) tagsList {
 	tagsList := make(tagsList)
 
 	// Robustly read only supported tags via tag.Get
-	if v := tag.Get(RST_MIN); v != "" {
-		tagsList[tagName(RST_MIN)] = tagValue(v)
-	}
-	if v := tag.Get(RST_MAX); v != "" {
-		tagsList[tagName(RST_MAX)] = tagValue(v)
-	}
-	if v := tag.Get(RST_REGEX); v != "" {
-		tagsList[tagName(RST_REGEX)] = tagValue(v)
-	}
-	if v := tag.Get(RST_DEFAULT); v != "" {
-		tagsList[tagName(RST_DEFAULT)] = tagValue(v)
-	}
-	if v := tag.Get(RST_CHOICE); v != "" {
-		tagsList[tagName(RST_CHOICE)] = tagValue(v)
-	}
-	if v := tag.Get(RST_FORBIDDEN); v != "" {
-		tagsList[tagName(RST_FORBIDDEN)] = tagValue(v)
+	for _, names := range [][]tagName{tagsOrder, condTags} {
+		for _, name := range names {
+			if v := tag.Get(tagKey(name, prefix)); v != "" {
+				tagsList[name] = tagValue(v)
+			}
+		}
 	}
 
 	return tagsList
 }
+
+// tagKey returns key of the built-in tag for the prefix.
+func tagKey(name tagName, prefix string) string {
+	return prefix + strings.TrimPrefix(string(name), RST_PREFIX)
+}
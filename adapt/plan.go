@@ -0,0 +1,230 @@
+package adapt
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// typePlan is the cached description of structure fields,
+// built once per structure type.
+type typePlan struct {
+	fields []fieldPlan
+	cross  bool // some fields have rules referencing other fields
+}
+
+type fieldPlan struct {
+	index int
+	name  string
+	rules *fieldRules // nil for fields without tags
+}
+
+// fieldRules holds parsed tags of a field and rules compiled
+// for every type of value they were applied to.
+type fieldRules struct {
+	tag   reflect.StructTag
+	tags  tagsList    // rules applied to the value alone
+	cross []crossRule // rules referencing other fields
+
+	compiled sync.Map // reflect.Type -> []boundRule
+
+	elemsOnce sync.Once
+	elems     *fieldRules
+
+	setOnce sync.Once
+	set     *fieldRules
+}
+
+// boundRule is a built-in rule compiled for a particular type.
+type boundRule struct {
+	name  tagName
+	value tagValue
+	apply valueRule
+}
+
+// planKey identifies plan of the type: tag names and field
+// names depend on options of the adapter.
+type planKey struct {
+	t      reflect.Type
+	prefix string
+	naming NamingStrategy
+}
+
+var typePlans sync.Map // planKey -> *typePlan
+
+// planFor returns cached plan of the structure type, building it if needed.
+func (a *Adapter) planFor(t reflect.Type) *typePlan {
+	key := planKey{t: t, prefix: a.tagPrefix(), naming: a.naming}
+	if plan, ok := typePlans.Load(key); ok {
+		return plan.(*typePlan)
+	}
+
+	plan := &typePlan{fields: make([]fieldPlan, t.NumField())}
+	for i := range plan.fields {
+		field := t.Field(i)
+		plan.fields[i] = fieldPlan{
+			index: i,
+			name:  a.naming.fieldName(field),
+			rules: newFieldRules(field.Tag, key.prefix),
+		}
+		if rules := plan.fields[i].rules; rules != nil && len(rules.cross) > 0 {
+			plan.cross = true
+		}
+	}
+
+	actual, _ := typePlans.LoadOrStore(key, plan)
+	return actual.(*typePlan)
+}
+
+func newFieldRules(tag reflect.StructTag, prefix string) *fieldRules {
+	if tag == "" {
+		return nil
+	}
+	tags, cross := splitCrossTags(parseStructTag(tag, prefix))
+	return &fieldRules{tag: tag, tags: tags, cross: cross}
+}
+
+// forType returns built-in rules of the field compiled for values of type t
+// in the order they are applied.
+func (fr *fieldRules) forType(t reflect.Type) []boundRule {
+	if rules, ok := fr.compiled.Load(t); ok {
+		return rules.([]boundRule)
+	}
+
+	rules := make([]boundRule, 0, len(fr.tags))
+	for _, tn := range tagsOrder {
+		if tn == RST_DEFAULT && fr.hasDefaultIf() {
+			continue
+		}
+		if tv, ok := fr.tags[tn]; ok {
+			apply, err := tagsMap[tn](tv, t, fr.tags)
+			if err != nil {
+				apply = invalidTagRule(apply)
+			}
+			rules = append(rules, boundRule{name: tn, value: tv, apply: apply})
+		}
+	}
+
+	actual, _ := fr.compiled.LoadOrStore(t, rules)
+	return actual.([]boundRule)
+}
+
+// elements returns rules of elements of slices, arrays and maps.
+func (fr *fieldRules) elements() *fieldRules {
+	fr.elemsOnce.Do(func() {
+		fr.elems = fr
+		if hasLengthRules(fr.tags) {
+			fr.elems = &fieldRules{tag: fr.tag, tags: elementTags(fr.tags)}
+		}
+	})
+	return fr.elems
+}
+
+// withoutDefault returns rules of the value explicitly set through
+// a pointer, to which rst-default is not applied.
+func (fr *fieldRules) withoutDefault() *fieldRules {
+	fr.setOnce.Do(func() {
+		fr.set = fr
+		if _, ok := fr.tags[RST_DEFAULT]; !ok {
+			return
+		}
+		tags := make(tagsList, len(fr.tags))
+		for tn, tv := range fr.tags {
+			if tn != RST_DEFAULT {
+				tags[tn] = tv
+			}
+		}
+		fr.set = &fieldRules{tag: fr.tag, tags: tags}
+	})
+	return fr.set
+}
+
+// failRule returns rule which always fails with the error.
+func failRule(err error) (valueRule, error) {
+	return func(reflect.Value) error {
+		return err
+	}, err
+}
+
+// invalidTagRule wraps errors of the rule compiled from invalid tag
+// with ErrInvalidTags.
+func invalidTagRule(apply valueRule) valueRule {
+	return func(value reflect.Value) error {
+		err := apply(value)
+		if err != nil && !errors.Is(err, ErrInvalidTags) {
+			return tagError{err}
+		}
+		return err
+	}
+}
+
+// snapshot keeps value of simple kinds without boxing it into interface,
+// so checking whether rule changed the value does not allocate.
+type snapshot struct {
+	kind reflect.Kind
+	i    int64
+	u    uint64
+	f    float64
+	s    string
+	b    bool
+	v    any // values of other kinds
+}
+
+func takeSnapshot(value reflect.Value) snapshot {
+	snap := snapshot{kind: value.Kind()}
+	switch snap.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		snap.i = value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		snap.u = value.Uint()
+	case reflect.Float32, reflect.Float64:
+		snap.f = value.Float()
+	case reflect.String:
+		snap.s = value.String()
+	case reflect.Bool:
+		snap.b = value.Bool()
+	default:
+		snap.v = indirectInterface(value)
+	}
+	return snap
+}
+
+func (snap snapshot) equal(value reflect.Value) bool {
+	if value.Kind() != snap.kind {
+		return false
+	}
+	switch snap.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return snap.i == value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return snap.u == value.Uint()
+	case reflect.Float32, reflect.Float64:
+		return snap.f == value.Float()
+	case reflect.String:
+		return snap.s == value.String()
+	case reflect.Bool:
+		return snap.b == value.Bool()
+	default:
+		return reflect.DeepEqual(snap.v, indirectInterface(value))
+	}
+}
+
+// value returns value kept in snapshot as a value of type t.
+func (snap snapshot) value(t reflect.Type) any {
+	value := reflect.New(t).Elem()
+	switch snap.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(snap.i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(snap.u)
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(snap.f)
+	case reflect.String:
+		value.SetString(snap.s)
+	case reflect.Bool:
+		value.SetBool(snap.b)
+	default:
+		return snap.v
+	}
+	return value.Interface()
+}
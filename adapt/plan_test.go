@@ -0,0 +1,156 @@
+package adapt
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchItem struct {
+	Name  string  `json:"name" rst-regex:"[^a-zA-Z0-9]+"`
+	Count int     `json:"count" rst-min:"1" rst-max:"100" rst-default:"10"`
+	Ratio float64 `json:"ratio" rst-max:"1.0"`
+	Level string  `json:"level" rst-choice:"debug||info||warn||error"`
+}
+
+type benchConfig struct {
+	Items  []benchItem `json:"items"`
+	Emails []string    `json:"emails" rst-regex:"[^a-z@.]+"`
+	Ports  []int       `json:"ports" rst-min:"1024" rst-max:"65535"`
+}
+
+func newBenchConfig(n int) benchConfig {
+	config := benchConfig{
+		Items:  make([]benchItem, n),
+		Emails: make([]string, n),
+		Ports:  make([]int, n),
+	}
+	for i := 0; i < n; i++ {
+		config.Items[i] = benchItem{Name: "item-" + strconv.Itoa(i), Count: i, Ratio: 1.5, Level: "trace"}
+		config.Emails[i] = "User" + strconv.Itoa(i) + "@example.com"
+		config.Ports[i] = i
+	}
+	return config
+}
+
+func Test_PlanCache(t *testing.T) {
+	t.Run("Concurrent Use", func(t *testing.T) {
+		ca := Adapter{}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := ca.AdaptStruct(benchItem{Name: "a-b", Count: 500, Ratio: 2, Level: "trace"})
+				assert.NoError(t, err)
+				assert.Equal(t, benchItem{Name: "ab", Count: 100, Ratio: 1, Level: "debug"}, result)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Rules Compiled Per Type", func(t *testing.T) {
+		type TestStruct struct {
+			Values []any `rst-max:"5"`
+		}
+		ca := Adapter{}
+
+		result, err := ca.AdaptStruct(TestStruct{Values: []any{7, 7.5, uint8(9)}})
+		assert.NoError(t, err)
+		assert.Equal(t, TestStruct{Values: []any{5, 5.0, uint8(5)}}, result)
+
+		rules := ca.planFor(reflect.TypeOf(TestStruct{})).fields[0].rules
+		compiled := 0
+		rules.compiled.Range(func(_, _ any) bool {
+			compiled++
+			return true
+		})
+		assert.Equal(t, 3, compiled)
+	})
+
+	t.Run("Changes Not Copied Without Report", func(t *testing.T) {
+		ca := Adapter{}
+		item := benchItem{Count: 500, Ratio: 2, Level: "trace"}
+		adapt := func() {
+			_, _ = ca.AdaptStruct(item)
+		}
+		report := func() {
+			_, _, _ = ca.AdaptStructWithReport(item)
+		}
+		adapt()
+		assert.Less(t, testing.AllocsPerRun(10, adapt), testing.AllocsPerRun(10, report))
+	})
+
+	t.Run("Parse Error Only When Applied", func(t *testing.T) {
+		type TestStruct struct {
+			Choice  int   `rst-choice:"1||abc"`
+			Default int   `rst-default:"abc"`
+			Min     *int  `rst-min:"abc"`
+			Max     []int `rst-max:"abc"`
+		}
+		ca := Adapter{}
+		one := 1
+
+		_, err := ca.AdaptStruct(TestStruct{Default: 1})
+		assert.NoError(t, err)
+
+		_, err = ca.AdaptStruct(TestStruct{Default: 1, Choice: 1})
+		assert.Error(t, err)
+
+		_, err = ca.AdaptStruct(TestStruct{})
+		assert.Error(t, err)
+
+		_, err = ca.AdaptStruct(TestStruct{Default: 1, Min: &one})
+		assert.Error(t, err)
+
+		_, err = ca.AdaptStruct(TestStruct{Default: 1, Max: []int{1}})
+		assert.Error(t, err)
+	})
+}
+
+// BenchmarkAdaptStruct compares adaptation with plans of types cached
+// by previous calls, with plans built anew for every call, and with
+// changes recorded in the report. Without report and logger changes
+// are not recorded, so values are not copied into interfaces.
+func BenchmarkAdaptStruct(b *testing.B) {
+	ba := Adapter{}
+
+	for _, mode := range []string{"Cached", "Uncached", "Report"} {
+		for _, n := range []int{10, 1000} {
+			b.Run(mode+"/"+strconv.Itoa(n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					// Адаптация меняет слайсы входа, поэтому он создается заново
+					b.StopTimer()
+					config := newBenchConfig(n)
+					if mode == "Uncached" {
+						clearPlans()
+					}
+					b.StartTimer()
+
+					var err error
+					if mode == "Report" {
+						_, _, err = ba.AdaptStructWithReport(config)
+					} else {
+						_, err = ba.AdaptStruct(config)
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// clearPlans removes cached plans of all types.
+func clearPlans() {
+	typePlans.Range(func(key, _ any) bool {
+		typePlans.Delete(key)
+		return true
+	})
+}
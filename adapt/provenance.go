@@ -0,0 +1,183 @@
+package adapt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Provenance describes where values of structure fields came from,
+// in order of the fields.
+type Provenance []FieldProvenance
+
+// FieldProvenance describes how the field got its final value.
+type FieldProvenance struct {
+	Path   string        `json:"path"`             // dotted path of the field
+	Source string        `json:"source,omitempty"` // source of the loaded value, empty if none set it
+	Raw    string        `json:"raw,omitempty"`    // value as written in the source
+	Rules  []AppliedRule `json:"rules,omitempty"`  // rules which changed the value in order
+	Value  string        `json:"value"`            // final value
+}
+
+// AppliedRule describes a change made by a rule.
+type AppliedRule struct {
+	Rule     string `json:"rule"`
+	TagValue string `json:"tag_value,omitempty"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+}
+
+func (r AppliedRule) String() string {
+	return fmt.Sprintf("%s: %s -> %s", r.Rule, r.Old, r.New)
+}
+
+// LoadWithProvenance works as Load and additionally returns provenance
+// of every field: its source, the raw value read and rules applied.
+// If rules fail, provenance is returned along with the error.
+func (l *Loader) LoadWithProvenance(out any) (Provenance, error) {
+	origins, changes, err := l.load(out)
+	if origins == nil {
+		return nil, err
+	}
+
+	b := provenanceBuilder{adapter: l.adapter, origins: origins, changes: changes}
+	b.collect(reflect.ValueOf(out).Elem(), "")
+	return b.result, err
+}
+
+// Field returns provenance of the field by its dotted path.
+func (p Provenance) Field(path string) (FieldProvenance, bool) {
+	for _, field := range p {
+		if field.Path == path {
+			return field, true
+		}
+	}
+	return FieldProvenance{}, false
+}
+
+// Table formats provenance as a text table with a header.
+func (p Provenance) Table() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSOURCE\tRAW\tRULES\tVALUE")
+	for _, field := range p {
+		rules := make([]string, len(field.Rules))
+		for i, rule := range field.Rules {
+			rules[i] = rule.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			field.Path, orDash(field.Source), orDash(field.Raw), orDash(strings.Join(rules, "; ")), field.Value)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// JSON formats provenance as indented JSON array.
+func (p Provenance) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// provenanceBuilder collects provenance of the fields of adapted structure.
+type provenanceBuilder struct {
+	adapter *Adapter
+	origins map[string]origin
+	changes []Change
+	result  Provenance
+}
+
+// collect adds fields of the structure depth-first. Nested structures
+// are listed only if a source set them as a whole or rules changed them,
+// other fields are always listed.
+func (b *provenanceBuilder) collect(input reflect.Value, parentPath string) {
+	for _, fp := range b.adapter.planFor(input.Type()).fields {
+		if !input.Type().Field(fp.index).IsExported() {
+			continue
+		}
+		path := joinPath(parentPath, fp.name)
+		value := input.Field(fp.index)
+
+		nested := reflect.Indirect(value)
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			if _, ok := b.origins[path]; ok || b.hasChanges(path) {
+				b.add(path, value, false)
+			}
+			b.collect(nested, path)
+			continue
+		}
+		b.add(path, value, true)
+	}
+}
+
+// add appends provenance of the field. Changes of values inside
+// leaf fields, such as elements of slices of structures, belong to them.
+func (b *provenanceBuilder) add(path string, value reflect.Value, leaf bool) {
+	field := FieldProvenance{Path: path, Value: formatProvenanceValue(value)}
+
+	// Источником поля считается ближайший предок, заданный целиком
+	for p := path; ; {
+		if o, ok := b.origins[p]; ok {
+			field.Source = o.source
+			if p == path {
+				field.Raw = o.raw
+			}
+			break
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+
+	for _, c := range b.changes {
+		if c.Path == path || leaf && strings.HasPrefix(c.Path, path+".") {
+			field.Rules = append(field.Rules, AppliedRule{
+				Rule:     c.Rule,
+				TagValue: c.TagValue,
+				Old:      formatProvenanceValue(reflect.ValueOf(c.Old)),
+				New:      formatProvenanceValue(reflect.ValueOf(c.New)),
+			})
+		}
+	}
+
+	b.result = append(b.result, field)
+}
+
+func (b *provenanceBuilder) hasChanges(path string) bool {
+	for _, c := range b.changes {
+		if c.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// formatProvenanceValue formats the value as written in YAML,
+// leaving strings unquoted.
+func formatProvenanceValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "null"
+	}
+	if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "null"
+		}
+		return formatProvenanceValue(value.Elem())
+	}
+	if value.Kind() == reflect.String {
+		return value.String()
+	}
+	if isSimpleType(value) {
+		return formatValue(value)
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
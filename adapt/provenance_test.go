@@ -0,0 +1,114 @@
+package adapt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadWithProvenance(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type TestStruct struct {
+		Name    string   `json:"name" rst-default:"app"`
+		Level   string   `json:"level" rst-choice:"debug||info"`
+		Server  Server   `json:"server"`
+		Backup  *Server  `json:"backup"`
+		Workers []uint   `json:"workers" rst-min:"1"`
+		Tags    []string `json:"tags"`
+	}
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("level: trace\nserver:\n  port: 5000\ntags: [a, b]\n"), 0o600))
+	t.Setenv("APP_WORKERS", "0,2")
+
+	newLoader := func() *Loader {
+		return NewLoader(&a, DefaultsSource(), FileSource(filename), EnvSource("APP"))
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		var test TestStruct
+		provenance, err := newLoader().LoadWithProvenance(&test)
+		require.NoError(t, err)
+
+		assert.Equal(t, Provenance{
+			{Path: "name", Source: SOURCE_DEFAULT, Raw: "app", Value: "app"},
+			{Path: "level", Source: filename, Raw: "trace", Value: "debug", Rules: []AppliedRule{
+				{Rule: RST_CHOICE, TagValue: "debug||info", Old: "trace", New: "debug"},
+			}},
+			{Path: "server.host", Source: SOURCE_DEFAULT, Raw: "localhost", Value: "localhost"},
+			{Path: "server.port", Source: filename, Raw: "5000", Value: "4010", Rules: []AppliedRule{
+				{Rule: RST_MAX, TagValue: "4010", Old: "5000", New: "4010"},
+			}},
+			{Path: "backup", Value: "null"},
+			{Path: "workers", Source: SOURCE_ENV, Raw: "0,2", Value: "[1 2]", Rules: []AppliedRule{
+				{Rule: RST_MIN, TagValue: "1", Old: "0", New: "1"},
+			}},
+			{Path: "tags", Source: filename, Raw: "[a, b]", Value: "[a b]"},
+		}, provenance)
+
+		field, ok := provenance.Field("server.port")
+		assert.True(t, ok)
+		assert.Equal(t, "4010", field.Value)
+		_, ok = provenance.Field("server")
+		assert.False(t, ok)
+	})
+
+	t.Run("Whole Structure", func(t *testing.T) {
+		var test TestStruct
+		provenance, err := NewLoader(&a, OverrideSource(map[string]any{
+			"backup": &Server{Host: "replica", Port: 1},
+		})).LoadWithProvenance(&test)
+		require.NoError(t, err)
+
+		backup, ok := provenance.Field("backup")
+		assert.True(t, ok)
+		assert.Equal(t, SOURCE_OVERRIDE, backup.Source)
+
+		port, _ := provenance.Field("backup.port")
+		assert.Equal(t, FieldProvenance{Path: "backup.port", Source: SOURCE_OVERRIDE, Value: "4000", Rules: []AppliedRule{
+			{Rule: RST_MIN, TagValue: "4000", Old: "1", New: "4000"},
+		}}, port)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		var test TestStruct
+		provenance, err := newLoader().LoadWithProvenance(&test)
+		require.NoError(t, err)
+
+		table := provenance.Table()
+		assert.Contains(t, table, "PATH         SOURCE")
+		assert.Regexp(t, `server\.port +\S+config\.yaml +5000 +rst-max: 5000 -> 4010 +4010\n`, table)
+		assert.Regexp(t, `backup +- +- +- +null\n`, table)
+
+		data, err := provenance.JSON()
+		require.NoError(t, err)
+		var decoded Provenance
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, provenance, decoded)
+		assert.Contains(t, string(data), `"rule": "rst-max"`)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := NewLoader(&a, FileSource("missing.yaml")).LoadWithProvenance(&TestStruct{})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		type Required struct {
+			Enabled bool   `json:"enabled"`
+			Key     string `json:"key" rst-required-if:"@enabled"`
+		}
+		provenance, err := NewLoader(&a, OverrideSource(map[string]any{"enabled": true})).LoadWithProvenance(&Required{})
+		assert.ErrorIs(t, err, ErrRequired)
+		assert.Equal(t, Provenance{
+			{Path: "enabled", Source: SOURCE_OVERRIDE, Raw: "true", Value: "true"},
+			{Path: "key", Value: ""},
+		}, provenance)
+	})
+}
@@ -0,0 +1,56 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Change describes a single modification made by a rule.
+type Change struct {
+	Path     string // dotted path of the field
+	Rule     string // name of the rule that changed the value
+	Old      any    // value before the rule was applied
+	New      any    // value after the rule was applied
+	TagValue string // value of the rule tag
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("field=%q reason=%q old_value=%v new_value=%v", c.Path, c.Rule, c.Old, c.New)
+}
+
+// AdaptStructWithReport works as AdaptStruct and additionally returns
+// every change made by rules in the order they were applied.
+func (a *Adapter) AdaptStructWithReport(input any) (any, []Change, error) {
+	st := a.newState()
+	st.report = true
+
+	adapted, err := a.adaptStruct(input, st)
+	if err != nil {
+		return nil, nil, err
+	}
+	return adapted, st.changes, nil
+}
+
+// AdaptInPlace applies rules described in structure tags to the
+// structure pointed to by ptr, writing adapted values directly into it,
+// including values behind nested pointers, slices and maps.
+// It returns every change made by rules. On error the structure
+// may be partially adapted.
+func (a *Adapter) AdaptInPlace(ptr any) ([]Change, error) {
+	ptrValue := reflect.ValueOf(ptr)
+	if ptrValue.Kind() != reflect.Ptr || ptrValue.IsNil() || ptrValue.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+
+	st := a.newState()
+	st.inPlace = true
+	st.report = true
+
+	if err := a.processField(ptrValue.Elem(), nil, st, ""); err != nil {
+		return st.changes, err
+	}
+	if err := a.adaptCross(ptrValue.Elem(), st); err != nil {
+		return st.changes, err
+	}
+	return st.changes, st.err()
+}
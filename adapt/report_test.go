@@ -0,0 +1,131 @@
+package adapt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AdaptStructWithReport(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type TestStruct struct {
+		Server Server   `json:"server"`
+		Levels []string `json:"levels" rst-choice:"debug||info"`
+		Count  int      `rst-max:"10"`
+	}
+
+	t.Run("Changes", func(t *testing.T) {
+		test := TestStruct{
+			Server: Server{Port: 5000},
+			Levels: []string{"info", "trace"},
+			Count:  5,
+		}
+
+		result, changes, err := a.AdaptStructWithReport(test)
+		assert.NoError(t, err)
+		assert.Equal(t, TestStruct{
+			Server: Server{Host: "localhost", Port: 4010},
+			Levels: []string{"info", "debug"},
+			Count:  5,
+		}, result)
+		assert.Equal(t, []Change{
+			{Path: "server.host", Rule: RST_DEFAULT, Old: "", New: "localhost", TagValue: "localhost"},
+			{Path: "server.port", Rule: RST_MAX, Old: 5000, New: 4010, TagValue: "4010"},
+			{Path: "levels", Rule: RST_CHOICE, Old: "trace", New: "debug", TagValue: "debug||info"},
+		}, changes)
+	})
+
+	t.Run("No Changes", func(t *testing.T) {
+		test := TestStruct{Server: Server{Host: "db", Port: 4001}}
+
+		_, changes, err := a.AdaptStructWithReport(test)
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		result, changes, err := a.AdaptStructWithReport(1)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		assert.Nil(t, result)
+		assert.Nil(t, changes)
+	})
+}
+
+func Test_AdaptInPlace(t *testing.T) {
+	type Item struct {
+		Count int `json:"count" rst-min:"1"`
+	}
+
+	type TestStruct struct {
+		Item      *Item           `json:"item"`
+		Items     []*Item         `json:"items"`
+		ItemMap   map[string]Item `json:"item_map"`
+		Ports     map[string]int  `json:"ports" rst-max:"100"`
+		Value     any             `json:"value"`
+		Pointer   any             `json:"pointer"`
+		Ratio     *float64        `json:"ratio" rst-default:"0.5"`
+		Unchanged int             `json:"unchanged" rst-max:"10"`
+	}
+
+	newTestStruct := func() TestStruct {
+		return TestStruct{
+			Item:      &Item{},
+			Items:     []*Item{{Count: 5}, {Count: -1}},
+			ItemMap:   map[string]Item{"a": {}},
+			Ports:     map[string]int{"http": 80, "https": 443},
+			Value:     Item{},
+			Pointer:   &Item{},
+			Unchanged: 3,
+		}
+	}
+
+	t.Run("Adapt Through Pointers", func(t *testing.T) {
+		test := newTestStruct()
+		item, items, ports, pointer := test.Item, test.Items, test.Ports, test.Pointer
+
+		changes, err := a.AdaptInPlace(&test)
+		assert.NoError(t, err)
+
+		// Значения изменены по месту, без замены указателей и карт
+		assert.Same(t, item, test.Item)
+		assert.Same(t, pointer, test.Pointer)
+		assert.Equal(t, 1, item.Count)
+		assert.Equal(t, 5, items[0].Count)
+		assert.Equal(t, 1, items[1].Count)
+		assert.Equal(t, Item{Count: 1}, test.ItemMap["a"])
+		assert.Equal(t, map[string]int{"http": 80, "https": 100}, ports)
+		assert.Equal(t, Item{Count: 1}, test.Value)
+		assert.Equal(t, 1, pointer.(*Item).Count)
+		assert.Equal(t, 0.5, *test.Ratio)
+		assert.Len(t, changes, 7)
+		assert.Contains(t, changes, Change{Path: "ports", Rule: RST_MAX, Old: 443, New: 100, TagValue: "100"})
+	})
+
+	t.Run("Same Result As AdaptStruct", func(t *testing.T) {
+		expected, err := a.AdaptStruct(newTestStruct())
+		assert.NoError(t, err)
+
+		test := newTestStruct()
+		_, err = a.AdaptInPlace(&test)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, test)
+	})
+
+	t.Run("Pointer Required", func(t *testing.T) {
+		_, err := a.AdaptInPlace(newTestStruct())
+		assert.ErrorIs(t, err, ErrNotStruct)
+
+		var nilPtr *TestStruct
+		_, err = a.AdaptInPlace(nilPtr)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := a.AdaptInPlace(&IncorrectField{IntForMax: "fail"})
+		assert.ErrorIs(t, err, ErrInvalidTags)
+	})
+}
@@ -0,0 +1,82 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RuleFunc is the signature of user-defined rules.
+// It receives the raw tag value, the field value and the dotted
+// field path. Non-nil pointers are dereferenced before the call,
+// so value can be modified in place.
+type RuleFunc func(tagValue string, value reflect.Value, path string) error
+
+// CommentFunc builds the YAML comment text for a user-defined rule.
+type CommentFunc func(tagValue string) string
+
+type customRule struct {
+	name    tagName
+	apply   RuleFunc
+	comment CommentFunc
+}
+
+// RegisterRule adds a user-defined rule read from the struct tag
+// with the given name. The name must start with the tag prefix ("rst-"
+// unless changed with WithTagPrefix) and must not clash with built-in
+// or already registered rules. Custom rules run
+// after the built-in ones, in registration order.
+// Rules must be registered before the adapter is used.
+func (a *Adapter) RegisterRule(name string, fn RuleFunc) error {
+	return a.addRule(customRule{name: tagName(name), apply: fn})
+}
+
+func (a *Adapter) addRule(rule customRule) error {
+	if rule.apply == nil {
+		return fmt.Errorf("rule %s: %w", rule.name, ErrInvalidRule)
+	}
+	if err := a.checkRuleName(string(rule.name)); err != nil {
+		return err
+	}
+	if a.customRule(rule.name) != nil {
+		return fmt.Errorf("rule %s: %w", rule.name, ErrRuleExists)
+	}
+
+	a.rules = append(a.rules, rule)
+	return nil
+}
+
+// RegisterRuleComment sets the function used by GenerateStructYAML
+// to describe a registered custom rule in YAML comments.
+func (a *Adapter) RegisterRuleComment(name string, fn CommentFunc) error {
+	rule := a.customRule(tagName(name))
+	if rule == nil || fn == nil {
+		return fmt.Errorf("rule %s: %w", name, ErrInvalidRule)
+	}
+	rule.comment = fn
+	return nil
+}
+
+func (a *Adapter) customRule(name tagName) *customRule {
+	for i := range a.rules {
+		if a.rules[i].name == name {
+			return &a.rules[i]
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) checkRuleName(name string) error {
+	prefix := a.tagPrefix()
+	if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+		return fmt.Errorf("rule %s: name must start with %q: %w", name, prefix, ErrInvalidRule)
+	}
+	for _, names := range [][]tagName{tagsOrder, condTags} {
+		for _, builtin := range names {
+			if name == tagKey(builtin, prefix) {
+				return fmt.Errorf("rule %s: %w", name, ErrRuleExists)
+			}
+		}
+	}
+	return nil
+}
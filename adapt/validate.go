@@ -0,0 +1,72 @@
+package adapt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Violation describes a field value that does not satisfy a rule.
+type Violation struct {
+	Path     string // dotted path of the field
+	Rule     string // name of the failed rule
+	Value    any    // current value of the field
+	Expected any    // value AdaptStruct would set
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("field %s, tag %s: value %v, expected %v", v.Path, v.Rule, v.Value, v.Expected)
+}
+
+// ValidationError lists every violation found by Validate.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v
+	}
+	return errs
+}
+
+// Validate checks input structure against rules described in
+// structure tags without modifying it. Values which AdaptStruct
+// would change are reported as *ValidationError. Filling unset
+// values with rst-default or rst-default-if is not considered a violation.
+// Fields required by rst-required-if are reported with ErrRequired.
+func (a *Adapter) Validate(input any) error {
+	inputValue := reflect.ValueOf(input)
+
+	if reflect.Indirect(inputValue).Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	st := a.newState()
+	st.dryRun = true
+	st.adapted = make(map[string][]reflect.Value)
+
+	if err := a.processField(inputValue, nil, st, ""); err != nil {
+		return err
+	}
+	if err := a.adaptCross(reflect.Indirect(inputValue), st); err != nil {
+		return err
+	}
+	if err := st.err(); err != nil {
+		return err
+	}
+
+	if len(st.violations) > 0 {
+		return &ValidationError{Violations: st.violations}
+	}
+	return nil
+}
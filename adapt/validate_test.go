@@ -0,0 +1,68 @@
+package adapt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	type Nested struct {
+		Level string `json:"level" rst-choice:"debug||info"`
+	}
+
+	type TestStruct struct {
+		Port    int            `json:"port" rst-min:"1" rst-max:"65535" rst-default:"8080"`
+		Name    string         `json:"name" rst-default:"app"`
+		Ratio   *float64       `json:"ratio" rst-max:"1.0"`
+		Workers []int          `json:"workers" rst-min:"1"`
+		Limits  map[string]int `json:"limits" rst-max:"10"`
+		Log     Nested         `json:"log"`
+	}
+
+	t.Run("Valid Struct", func(t *testing.T) {
+		test := TestStruct{Port: 80, Workers: []int{1, 2}, Log: Nested{Level: "info"}}
+
+		assert.NoError(t, a.Validate(test))
+		assert.NoError(t, a.Validate(&test))
+	})
+
+	t.Run("Defaults Are Not Violations", func(t *testing.T) {
+		assert.NoError(t, a.Validate(TestStruct{}))
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		ratio := 1.5
+		test := TestStruct{
+			Port:    70000,
+			Ratio:   &ratio,
+			Workers: []int{0, 2},
+			Limits:  map[string]int{"a": 20},
+			Log:     Nested{Level: "trace"},
+		}
+
+		err := a.Validate(&test)
+		var verr *ValidationError
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, []Violation{
+			{Path: "port", Rule: RST_MAX, Value: 70000, Expected: 65535},
+			{Path: "ratio", Rule: RST_MAX, Value: 1.5, Expected: 1.0},
+			{Path: "workers", Rule: RST_MIN, Value: 0, Expected: 1},
+			{Path: "limits", Rule: RST_MAX, Value: 20, Expected: 10},
+			{Path: "log.level", Rule: RST_CHOICE, Value: "trace", Expected: "debug"},
+		}, verr.Violations)
+
+		// Входная структура не изменяется
+		assert.Equal(t, 70000, test.Port)
+		assert.Equal(t, 1.5, ratio)
+		assert.Equal(t, []int{0, 2}, test.Workers)
+		assert.Equal(t, map[string]int{"a": 20}, test.Limits)
+		assert.Equal(t, "trace", test.Log.Level)
+	})
+
+	t.Run("Invalid Input", func(t *testing.T) {
+		assert.ErrorIs(t, a.Validate(1), ErrNotStruct)
+		assert.ErrorIs(t, a.Validate(IncorrectField{IntForMax: "fail"}), ErrInvalidTags)
+	})
+}
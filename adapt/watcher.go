@@ -0,0 +1,218 @@
+package adapt
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DEFAULT_POLL_INTERVAL is the interval Watcher checks files by default.
+const DEFAULT_POLL_INTERVAL = time.Second
+
+// FieldDiff describes a field whose value differs between two
+// versions of a structure.
+type FieldDiff struct {
+	Path string // dotted path of the field
+	Old  any    // previous value, nil for nil pointers
+	New  any    // new value, nil for nil pointers
+}
+
+// Watcher keeps the structure loaded by Loader up to date: it polls
+// files of FileSource sources and reloads the structure when any of
+// them changes. Invalid reloads are rejected, keeping the last valid
+// structure.
+type Watcher[T any] struct {
+	loader   *Loader
+	files    []string
+	interval time.Duration
+	onError  func(error)
+
+	current atomic.Pointer[T]
+
+	mu          sync.Mutex // serializes reloads
+	stamps      map[string]fileStamp
+	subscribers []func(cfg *T, diff []FieldDiff)
+}
+
+// WatchOption configures Watcher created by NewWatcher.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+	onError  func(error)
+}
+
+// WithPollInterval sets interval of checking files for changes.
+// Intervals which are not positive are replaced with DEFAULT_POLL_INTERVAL.
+func WithPollInterval(interval time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = interval
+	}
+}
+
+// WithReloadErrorHandler sets function receiving errors of rejected
+// reloads. By default they are written to the logger of the adapter.
+func WithReloadErrorHandler(fn func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+// fileStamp identifies version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// NewWatcher loads the structure of type T with the loader and returns
+// Watcher of it. Files are not polled until Run is called.
+func NewWatcher[T any](loader *Loader, opts ...WatchOption) (*Watcher[T], error) {
+	options := watchOptions{interval: DEFAULT_POLL_INTERVAL}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// time.NewTicker паникует на неположительном интервале
+	if options.interval <= 0 {
+		options.interval = DEFAULT_POLL_INTERVAL
+	}
+	if options.onError == nil {
+		options.onError = func(err error) {
+			loader.adapter.logf("reload rejected: %v", err)
+		}
+	}
+
+	w := &Watcher[T]{
+		loader:   loader,
+		interval: options.interval,
+		onError:  options.onError,
+		stamps:   make(map[string]fileStamp),
+	}
+	for _, src := range loader.sources {
+		if file, ok := src.(fileSource); ok {
+			w.files = append(w.files, string(file))
+		}
+	}
+
+	w.updateStamps()
+	cfg := new(T)
+	if _, err := loader.Load(cfg); err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+	return w, nil
+}
+
+// Get returns the current structure. It must not be modified,
+// reloads replace it with a new one.
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// Subscribe registers function called after every reload which changed
+// the structure, with the new structure and changed fields in order.
+// Subscribers are called one by one and must not call Subscribe or Reload.
+func (w *Watcher[T]) Subscribe(fn func(cfg *T, diff []FieldDiff)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Run polls files until the context is done.
+func (w *Watcher[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.updateStamps() {
+				// Ошибка уже передана обработчику
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// Reload loads the structure from all sources regardless of changes
+// of files, e.g. on SIGHUP. If loading fails, the current structure
+// is kept and the error is passed to the error handler and returned.
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg := new(T)
+	if _, err := w.loader.Load(cfg); err != nil {
+		w.onError(err)
+		return err
+	}
+
+	old := w.current.Load()
+	diff := w.loader.adapter.diffFields(reflect.ValueOf(old).Elem(), reflect.ValueOf(cfg).Elem(), "", nil)
+	if len(diff) == 0 {
+		return nil
+	}
+
+	w.current.Store(cfg)
+	for _, fn := range w.subscribers {
+		fn(cfg, diff)
+	}
+	return nil
+}
+
+// updateStamps records versions of the files and reports
+// whether any of them changed.
+func (w *Watcher[T]) updateStamps() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changed := false
+	for _, file := range w.files {
+		var stamp fileStamp
+		if info, err := os.Stat(file); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+		}
+		if prev, ok := w.stamps[file]; !ok || prev != stamp {
+			w.stamps[file] = stamp
+			changed = true
+		}
+	}
+	return changed
+}
+
+// diffFields appends fields of the structures whose values differ.
+// Nested structures are compared field by field, other values
+// as a whole.
+func (a *Adapter) diffFields(prev, next reflect.Value, parentPath string, diff []FieldDiff) []FieldDiff {
+	for _, fp := range a.planFor(prev.Type()).fields {
+		if !prev.Type().Field(fp.index).IsExported() {
+			continue
+		}
+		path := joinPath(parentPath, fp.name)
+		oldValue := reflect.Indirect(prev.Field(fp.index))
+		newValue := reflect.Indirect(next.Field(fp.index))
+
+		if oldValue.Kind() == reflect.Struct && newValue.Kind() == reflect.Struct && oldValue.Type() != timeType {
+			diff = a.diffFields(oldValue, newValue, path, diff)
+			continue
+		}
+
+		oldAny, newAny := diffValue(oldValue), diffValue(newValue)
+		if !reflect.DeepEqual(oldAny, newAny) {
+			diff = append(diff, FieldDiff{Path: path, Old: oldAny, New: newAny})
+		}
+	}
+	return diff
+}
+
+func diffValue(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
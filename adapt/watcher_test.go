@@ -0,0 +1,129 @@
+package adapt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Watcher(t *testing.T) {
+	type Server struct {
+		Host string `json:"host" rst-default:"localhost"`
+		Port int    `json:"port" rst-min:"4000" rst-max:"4010"`
+	}
+
+	type TestStruct struct {
+		Name   string   `json:"name"`
+		Server Server   `json:"server"`
+		Backup *Server  `json:"backup"`
+		Tags   []string `json:"tags"`
+	}
+
+	writeFile := func(t *testing.T, filename, content string) {
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	}
+
+	newWatcher := func(t *testing.T, content string, opts ...WatchOption) (*Watcher[TestStruct], string) {
+		filename := filepath.Join(t.TempDir(), "config.yaml")
+		writeFile(t, filename, content)
+
+		w, err := NewWatcher[TestStruct](NewLoader(&a, DefaultsSource(), FileSource(filename)), opts...)
+		require.NoError(t, err)
+		return w, filename
+	}
+
+	t.Run("Reload", func(t *testing.T) {
+		w, filename := newWatcher(t, "name: app\nserver:\n  port: 4001\n")
+		first := w.Get()
+		assert.Equal(t, &TestStruct{Name: "app", Server: Server{Host: "localhost", Port: 4001}}, first)
+
+		var got []FieldDiff
+		w.Subscribe(func(cfg *TestStruct, diff []FieldDiff) {
+			assert.Same(t, w.Get(), cfg)
+			got = diff
+		})
+
+		writeFile(t, filename, "name: app\nserver:\n  port: 5000\nbackup:\n  host: db\ntags: [a]\n")
+		require.NoError(t, w.Reload())
+
+		assert.Equal(t, []FieldDiff{
+			{Path: "server.port", Old: 4001, New: 4010},
+			{Path: "backup", Old: nil, New: Server{Host: "db", Port: 4000}},
+			{Path: "tags", Old: []string(nil), New: []string{"a"}},
+		}, got)
+		assert.Equal(t, 4001, first.Server.Port)
+		assert.Equal(t, 4010, w.Get().Server.Port)
+
+		got = nil
+		require.NoError(t, w.Reload())
+		assert.Nil(t, got)
+	})
+
+	t.Run("Rejected Reload", func(t *testing.T) {
+		var handled error
+		w, filename := newWatcher(t, "name: app\n", WithReloadErrorHandler(func(err error) {
+			handled = err
+		}))
+		w.Subscribe(func(*TestStruct, []FieldDiff) {
+			t.Error("subscriber called on rejected reload")
+		})
+
+		writeFile(t, filename, "server:\n  port: abc\n")
+		err := w.Reload()
+
+		var yerr *YAMLError
+		assert.ErrorAs(t, err, &yerr)
+		assert.Equal(t, err, handled)
+		assert.Equal(t, "app", w.Get().Name)
+	})
+
+	t.Run("Poll", func(t *testing.T) {
+		w, filename := newWatcher(t, "name: app\n", WithPollInterval(10*time.Millisecond))
+
+		changed := make(chan []FieldDiff, 1)
+		w.Subscribe(func(_ *TestStruct, diff []FieldDiff) {
+			changed <- diff
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			w.Run(ctx)
+			close(done)
+		}()
+
+		writeFile(t, filename, "name: service\n")
+		select {
+		case diff := <-changed:
+			assert.Equal(t, []FieldDiff{{Path: "name", Old: "app", New: "service"}}, diff)
+		case <-time.After(5 * time.Second):
+			t.Fatal("change not detected")
+		}
+		assert.Equal(t, "service", w.Get().Name)
+
+		cancel()
+		<-done
+	})
+
+	t.Run("Invalid Poll Interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			w, _ := newWatcher(t, "name: app\n", WithPollInterval(interval))
+			assert.Equal(t, DEFAULT_POLL_INTERVAL, w.interval)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			assert.NotPanics(t, func() { w.Run(ctx) })
+		}
+	})
+
+	t.Run("Invalid Initial Load", func(t *testing.T) {
+		_, err := NewWatcher[TestStruct](NewLoader(&a, FileSource("missing.yaml")))
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
@@ -7,11 +7,24 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/amakca/go-adapt/internal/structtag"
 )
 
 // GenerateStructYAML генерирует YAML файл структуры с комментариями из структурных тегов
 // Функция получает на вход структуру и возвращает строку с YAML представлением
 func GenerateStructYAML(input any) (string, error) {
+	return new(Adapter).generateStructYAML(input)
+}
+
+// GenerateStructYAML генерирует YAML структуры так же, как одноименная функция пакета,
+// но учитывает префикс тегов адаптера и дополнительно добавляет в комментарии
+// описания зарегистрированных пользовательских правил
+func (a *Adapter) GenerateStructYAML(input any) (string, error) {
+	return a.generateStructYAML(input)
+}
+
+func (a *Adapter) generateStructYAML(input any) (string, error) {
 	inputValue := reflect.ValueOf(input)
 
 	if reflect.Indirect(inputValue).Kind() != reflect.Struct {
@@ -21,7 +34,7 @@ func GenerateStructYAML(input any) (string, error) {
 	var result strings.Builder
 	result.WriteString("# Generated YAML structure with RST tags comments\n\n")
 
-	if err := generateStructYAMLRecursive(inputValue, "", &result, 0); err != nil {
+	if err := a.generateStructYAMLRecursive(inputValue, "", &result, 0); err != nil {
 		return "", err
 	}
 
@@ -51,7 +64,7 @@ func GenerateStructYAMLFile(input any, filename string) error {
 }
 
 // generateStructYAMLRecursive рекурсивно генерирует YAML для структуры
-func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *strings.Builder, indent int) error {
+func (a *Adapter) generateStructYAMLRecursive(input reflect.Value, fieldName string, result *strings.Builder, indent int) error {
 	// Обрабатываем указатели
 	if input.Kind() == reflect.Ptr {
 		if input.IsNil() {
@@ -77,6 +90,13 @@ func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *
 
 	switch input.Kind() {
 	case reflect.Struct:
+		if input.Type() == timeType {
+			if fieldName != "" {
+				result.WriteString(fmt.Sprintf("%s%s: %s\n", indentStr, fieldName, formatValue(input)))
+			}
+			break
+		}
+
 		if fieldName != "" {
 			result.WriteString(fmt.Sprintf("%s%s:\n", indentStr, fieldName))
 		}
@@ -91,25 +111,15 @@ func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *
 				continue
 			}
 
-			// Получаем имя поля из json тега или используем имя поля (по умолчанию в нижнем регистре)
-			jsonTag := field.Tag.Get("json")
-			name := strings.ToLower(field.Name)
-			if jsonTag != "" && jsonTag != "-" {
-				// Убираем omitempty если есть
-				if commaIdx := strings.Index(jsonTag, ","); commaIdx != -1 {
-					name = jsonTag[:commaIdx]
-				} else {
-					name = jsonTag
-				}
-			}
+			name := yamlFieldName(field)
 
 			// Комментарии печатаем без отступа
-			comment := generateCommentFromTags(field.Tag)
+			comment := a.generateCommentFromTags(field.Tag)
 			if comment != "" {
 				result.WriteString(fmt.Sprintf("# %s\n", comment))
 			}
 
-			if err := generateStructYAMLRecursive(value, name, result, indent+1); err != nil {
+			if err := a.generateStructYAMLRecursive(value, name, result, indent+1); err != nil {
 				return err
 			}
 		}
@@ -131,11 +141,11 @@ func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *
 				val = val.Elem()
 			}
 
-			if isSimpleKind(val.Kind()) {
+			if isSimpleType(val) {
 				result.WriteString(fmt.Sprintf("%s- %s\n", indentNext, formatValue(val)))
 			} else {
 				result.WriteString(fmt.Sprintf("%s- \n", indentNext))
-				if err := generateStructYAMLRecursive(val, "", result, indent+2); err != nil {
+				if err := a.generateStructYAMLRecursive(val, "", result, indent+2); err != nil {
 					return err
 				}
 			}
@@ -165,7 +175,7 @@ func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *
 					}
 				}
 			}
-			if err := generateStructYAMLRecursive(val, keyStr, result, indent+1); err != nil {
+			if err := a.generateStructYAMLRecursive(val, keyStr, result, indent+1); err != nil {
 				return err
 			}
 		}
@@ -181,20 +191,25 @@ func generateStructYAMLRecursive(input reflect.Value, fieldName string, result *
 	return nil
 }
 
-// local helper for YAML generator
-func isSimpleKind(k reflect.Kind) bool {
-	switch k {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
-		return true
-	default:
-		return false
+// yamlFieldName возвращает ключ поля в YAML: имя из json тега
+// или имя поля в нижнем регистре
+func yamlFieldName(field reflect.StructField) string {
+	jsonTag := field.Tag.Get(TAG_JSON)
+	if jsonTag == "" || jsonTag == "-" {
+		return strings.ToLower(field.Name)
+	}
+	// Убираем omitempty если есть
+	if commaIdx := strings.Index(jsonTag, ","); commaIdx != -1 {
+		jsonTag = jsonTag[:commaIdx]
+	}
+	if jsonTag == "" {
+		return strings.ToLower(field.Name)
 	}
+	return jsonTag
 }
 
 // generateCommentFromTags генерирует комментарий из структурных тегов
-func generateCommentFromTags(tag reflect.StructTag) string {
+func (a *Adapter) generateCommentFromTags(tag reflect.StructTag) string {
 	var comments []string
 
 	// Получаем info тег для основного описания
@@ -204,10 +219,10 @@ func generateCommentFromTags(tag reflect.StructTag) string {
 	}
 
 	// Парсим RST теги
-	tagsList := parseStructTag(tag)
+	tagsList := parseStructTag(tag, a.tagPrefix())
 
 	// Добавляем комментарии в детерминированном порядке
-	ordered := []tagName{RST_MIN, RST_MAX, RST_DEFAULT, RST_CHOICE, RST_FORBIDDEN, RST_REGEX}
+	ordered := []tagName{RST_MIN, RST_MAX, RST_MINLEN, RST_MAXLEN, RST_DEFAULT, RST_DEFAULT_IF, RST_CHOICE, RST_FORBIDDEN, RST_REGEX, RST_REQUIRED_IF}
 	for _, tn := range ordered {
 		if tv, ok := tagsList[tn]; ok {
 			comment := generateCommentForTag(tn, tv)
@@ -217,6 +232,18 @@ func generateCommentFromTags(tag reflect.StructTag) string {
 		}
 	}
 
+	// Пользовательские правила описываются после встроенных
+	for _, rule := range a.rules {
+		if rule.comment == nil {
+			continue
+		}
+		if tv := tag.Get(string(rule.name)); tv != "" {
+			if comment := rule.comment(tv); comment != "" {
+				comments = append(comments, comment)
+			}
+		}
+	}
+
 	if len(comments) == 0 {
 		return ""
 	}
@@ -226,6 +253,11 @@ func generateCommentFromTags(tag reflect.StructTag) string {
 
 // generateCommentForTag генерирует комментарий для конкретного тега
 func generateCommentForTag(tagName tagName, tagValue tagValue) string {
+	// Правила со ссылками на другие поля описываются отдельно
+	if isCrossRule(tagName, tagValue) {
+		return generateCommentForCrossRule(parseCrossRule(tagName, tagValue))
+	}
+
 	switch tagName {
 	case RST_MIN:
 		return fmt.Sprintf("минимальное значение - %s", tagValue)
@@ -233,6 +265,16 @@ func generateCommentForTag(tagName tagName, tagValue tagValue) string {
 	case RST_MAX:
 		return fmt.Sprintf("максимальное значение - %s", tagValue)
 
+	case RST_MINLEN:
+		minLen, pad, found := strings.Cut(string(tagValue), VAL_DELIMITER)
+		if found {
+			return fmt.Sprintf("минимальная длина - %s, дополняется: %s", minLen, pad)
+		}
+		return fmt.Sprintf("минимальная длина - %s", minLen)
+
+	case RST_MAXLEN:
+		return fmt.Sprintf("максимальная длина - %s", tagValue)
+
 	case RST_DEFAULT:
 		return fmt.Sprintf("значение по умолчанию - %s", tagValue)
 
@@ -255,18 +297,59 @@ func generateCommentForTag(tagName tagName, tagValue tagValue) string {
 		}
 
 	case RST_REGEX:
-		return fmt.Sprintf("регулярное выражение: %s", tagValue)
+		mode, pattern, arg, hasArg := structtag.ParseRegex(string(tagValue))
+		switch mode {
+		case REGEX_KEEP:
+			return fmt.Sprintf("сохраняются только совпадения с регулярным выражением: %s", pattern)
+		case REGEX_MATCH:
+			if hasArg {
+				return fmt.Sprintf("должно соответствовать регулярному выражению: %s, иначе: %q", pattern, arg)
+			}
+			return fmt.Sprintf("должно соответствовать регулярному выражению: %s, иначе значение по умолчанию", pattern)
+		case REGEX_REPLACE:
+			return fmt.Sprintf("замена по регулярному выражению: %s на %q", pattern, arg)
+		default:
+			return fmt.Sprintf("регулярное выражение: %s", pattern)
+		}
 
 	default:
 		return ""
 	}
 }
 
+// generateCommentForCrossRule генерирует комментарий для правила со ссылкой на другое поле
+func generateCommentForCrossRule(rule crossRule) string {
+	if rule.err != nil {
+		return ""
+	}
+
+	cond := fmt.Sprintf("задано поле %s", rule.ref)
+	if rule.hasCond {
+		cond = fmt.Sprintf("поле %s равно %s", rule.ref, rule.cond)
+	}
+
+	switch rule.name {
+	case RST_MIN:
+		return fmt.Sprintf("минимальное значение - значение поля %s", rule.ref)
+	case RST_MAX:
+		return fmt.Sprintf("максимальное значение - значение поля %s", rule.ref)
+	case RST_DEFAULT_IF:
+		return fmt.Sprintf("значение по умолчанию, если %s - %s", cond, rule.arg)
+	default:
+		return fmt.Sprintf("обязательно, если %s", cond)
+	}
+}
+
 // formatValue форматирует значение для YAML
 func formatValue(value reflect.Value) string {
+	// Длительности и время печатаются в том же виде, что и в тегах
+	if isTimeType(value.Type()) {
+		return formatTimeValue(value)
+	}
+
 	switch value.Kind() {
 	case reflect.String:
-		return fmt.Sprintf("\"%s\"", value.String())
+		return strconv.Quote(value.String())
 
 	case reflect.Bool:
 		return strconv.FormatBool(value.Bool())
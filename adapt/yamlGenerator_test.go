@@ -2,6 +2,7 @@ package adapt
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -161,6 +162,80 @@ func Test_GenerateStructYAML(t *testing.T) {
 	}
 }
 
+func Test_GenerateStructYAML_LengthRules(t *testing.T) {
+	type LengthStruct struct {
+		Name  string   `json:"name" rst-maxlen:"64" info:"Имя"`
+		Code  string   `json:"code" rst-minlen:"6**0" rst-maxlen:"6"`
+		Hosts []string `json:"hosts" rst-minlen:"1" rst-default:"localhost"`
+	}
+
+	result, err := GenerateStructYAML(LengthStruct{Hosts: []string{"a"}})
+	assert.NoError(t, err)
+	assert.Contains(t, result, "# Имя; максимальная длина - 64\n")
+	assert.Contains(t, result, "# минимальная длина - 6, дополняется: 0; максимальная длина - 6\n")
+	assert.Contains(t, result, "# минимальная длина - 1; значение по умолчанию - localhost\n")
+}
+
+func Test_GenerateStructYAML_RegexModes(t *testing.T) {
+	type RegexStruct struct {
+		Strip   string `rst-regex:"strip:[^0-9]"`
+		Keep    string `rst-regex:"keep:[a-z]+"`
+		Match   string `rst-regex:"match:^[0-9]{5}$**00000"`
+		Default string `rst-regex:"match:^[a-z]+$" rst-default:"localhost"`
+		Replace string `rst-regex:"replace:(\\w+)@**$1 at "`
+	}
+
+	result, err := GenerateStructYAML(RegexStruct{})
+	assert.NoError(t, err)
+	assert.Contains(t, result, "# регулярное выражение: [^0-9]\n")
+	assert.Contains(t, result, "# сохраняются только совпадения с регулярным выражением: [a-z]+\n")
+	assert.Contains(t, result, `# должно соответствовать регулярному выражению: ^[0-9]{5}$, иначе: "00000"`)
+	assert.Contains(t, result, "# значение по умолчанию - localhost; должно соответствовать регулярному выражению: ^[a-z]+$, иначе значение по умолчанию\n")
+	assert.Contains(t, result, `# замена по регулярному выражению: (\w+)@ на "$1 at "`)
+}
+
+func Test_GenerateStructYAML_Time(t *testing.T) {
+	type TimeStruct struct {
+		Timeout  time.Duration   `json:"timeout" rst-min:"1s" info:"Таймаут"`
+		Start    time.Time       `json:"start"`
+		Retries  []time.Duration `json:"retries"`
+		Deadline *time.Time      `json:"deadline"`
+	}
+
+	result, err := GenerateStructYAML(TimeStruct{
+		Timeout: 90 * time.Second,
+		Start:   time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Retries: []time.Duration{500 * time.Millisecond, time.Hour},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `# Generated YAML structure with RST tags comments
+
+# Таймаут; минимальное значение - 1s
+  timeout: 1m30s
+  start: 2024-06-01T12:00:00Z
+  retries:
+    - 500ms
+    - 1h0m0s
+  deadline: null
+`, result)
+}
+
+func Test_GenerateStructYAML_CrossFieldRules(t *testing.T) {
+	type CrossStruct struct {
+		MinConns int    `json:"min_conns"`
+		MaxConns int    `json:"max_conns" rst-min:"@min_conns" rst-max:"100"`
+		Enabled  bool   `json:"enabled"`
+		Key      string `json:"key" rst-required-if:"@enabled" info:"Ключ"`
+		Mode     string `json:"mode" rst-default-if:"@enabled=true**strict" rst-default:"off"`
+	}
+
+	result, err := GenerateStructYAML(CrossStruct{})
+	assert.NoError(t, err)
+	assert.Contains(t, result, "# минимальное значение - значение поля min_conns; максимальное значение - 100\n")
+	assert.Contains(t, result, "# Ключ; обязательно, если задано поле enabled\n")
+	assert.Contains(t, result, "# значение по умолчанию - off; значение по умолчанию, если поле enabled равно true - strict\n")
+}
+
 func Test_GenerateStructYAML_ErrorCases(t *testing.T) {
 	tests := []struct {
 		name        string
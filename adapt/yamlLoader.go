@@ -0,0 +1,229 @@
+package adapt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLError describes a YAML value that could not be decoded
+// into the structure field.
+type YAMLError struct {
+	Path   string // dotted path of the field
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *YAMLError) Error() string {
+	return fmt.Sprintf("yaml: line %d, column %d: field %s: %v", e.Line, e.Column, e.Path, e.Err)
+}
+
+func (e *YAMLError) Unwrap() error {
+	return e.Err
+}
+
+var errYAMLKind = errors.New("unexpected yaml node kind")
+
+// LoadYAML reads YAML file into structure pointed to by out
+// and applies rules described in structure tags.
+func (a *Adapter) LoadYAML(filename string, out any) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := a.DecodeYAML(file, out); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// DecodeYAML reads YAML document from r into structure pointed to by out
+// and applies rules described in structure tags.
+// Keys are matched the same way GenerateStructYAML names them: name from
+// json tag or field name in lower case. Unknown keys are ignored.
+func (a *Adapter) DecodeYAML(r io.Reader, out any) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	if err := a.decodeYAML(r, outValue.Elem(), skipMark); err != nil {
+		return err
+	}
+
+	_, err := a.AdaptInPlace(out)
+	return err
+}
+
+// decodeYAML reads YAML document from r into the structure value,
+// calling mark with paths and raw values of the fields set from the document.
+func (a *Adapter) decodeYAML(r io.Reader, value reflect.Value, mark func(path, raw string)) error {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if len(doc.Content) > 0 {
+		return a.decodeYAMLNode(doc.Content[0], value, "", mark)
+	}
+	return nil
+}
+
+// decodeYAMLNode recursively writes YAML node into addressable value.
+// Fields holding values other than structures are reported to mark
+// as set, structures are set field by field.
+func (a *Adapter) decodeYAMLNode(node *yaml.Node, value reflect.Value, path string, mark func(path, raw string)) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		value.Set(reflect.Zero(value.Type()))
+		mark(path, node.Value)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return a.decodeYAMLNode(node, value.Elem(), path, mark)
+
+	case reflect.Struct:
+		if value.Type() == timeType {
+			if err := node.Decode(value.Addr().Interface()); err != nil {
+				return yamlError(node, path, err)
+			}
+			mark(path, yamlRaw(node))
+			break
+		}
+		if node.Kind != yaml.MappingNode {
+			return yamlError(node, path, errYAMLKind)
+		}
+
+		fields := yamlFields(value.Type())
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			idx, ok := fields[node.Content[i].Value]
+			if !ok {
+				continue
+			}
+			field := value.Type().Field(idx)
+			if err := a.decodeYAMLNode(node.Content[i+1], value.Field(idx), joinPath(path, a.naming.fieldName(field)), mark); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return yamlError(node, path, errYAMLKind)
+		}
+
+		slice := reflect.MakeSlice(value.Type(), len(node.Content), len(node.Content))
+		for i, item := range node.Content {
+			if err := a.decodeYAMLNode(item, slice.Index(i), path, skipMark); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		mark(path, yamlRaw(node))
+
+	case reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return yamlError(node, path, errYAMLKind)
+		}
+		if len(node.Content) > value.Len() {
+			return yamlError(node, path, fmt.Errorf("too many elements for %s", value.Type()))
+		}
+
+		for i, item := range node.Content {
+			if err := a.decodeYAMLNode(item, value.Index(i), path, skipMark); err != nil {
+				return err
+			}
+		}
+		mark(path, yamlRaw(node))
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return yamlError(node, path, errYAMLKind)
+		}
+
+		mapValue := reflect.MakeMapWithSize(value.Type(), len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := reflect.New(value.Type().Key()).Elem()
+			if err := node.Content[i].Decode(key.Addr().Interface()); err != nil {
+				return yamlError(node.Content[i], path, err)
+			}
+			val := reflect.New(value.Type().Elem()).Elem()
+			if err := a.decodeYAMLNode(node.Content[i+1], val, path, skipMark); err != nil {
+				return err
+			}
+			mapValue.SetMapIndex(key, val)
+		}
+		value.Set(mapValue)
+		mark(path, yamlRaw(node))
+
+	default:
+		if err := node.Decode(value.Addr().Interface()); err != nil {
+			return yamlError(node, path, err)
+		}
+		mark(path, yamlRaw(node))
+	}
+
+	return nil
+}
+
+// skipMark ignores paths of set fields. It is used for values
+// inside slices and maps, which are set as a whole.
+func skipMark(string, string) {}
+
+// yamlRaw returns value of the node as written in the document,
+// collections in flow style.
+func yamlRaw(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	flow := *node
+	flow.Style |= yaml.FlowStyle
+	data, err := yaml.Marshal(&flow)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// yamlFields maps YAML keys of exported structure fields to their indexes.
+func yamlFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields[yamlFieldName(field)] = i
+	}
+	return fields
+}
+
+func yamlError(node *yaml.Node, path string, err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) == 1 {
+		// Позиция уже известна, оставляем только описание ошибки
+		msg := typeErr.Errors[0]
+		if strings.HasPrefix(msg, "line ") {
+			if idx := strings.Index(msg, ": "); idx != -1 {
+				msg = msg[idx+2:]
+			}
+		}
+		err = errors.New(msg)
+	}
+	return &YAMLError{Path: path, Line: node.Line, Column: node.Column, Err: err}
+}
@@ -0,0 +1,141 @@
+package adapt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type yamlServer struct {
+	Host string `json:"host" rst-default:"localhost" info:"Хост сервера"`
+	Port int    `json:"port" rst-min:"4000" rst-max:"4010" rst-default:"4002" info:"Порт сервера"`
+}
+
+type yamlConfig struct {
+	Server   yamlServer        `json:"server" info:"Настройки сервера"`
+	Replicas []yamlServer      `json:"replicas"`
+	Level    string            `rst-choice:"debug||info"`
+	Ratio    *float64          `json:"ratio,omitempty" rst-max:"1"`
+	Labels   map[string]string `json:"labels"`
+	Ports    [2]int            `json:"ports"`
+	Extra    any               `json:"extra"`
+	internal int
+}
+
+func Test_DecodeYAML(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		ratio := 0.5
+		config := yamlConfig{
+			Server:   yamlServer{Host: "0.0.0.0", Port: 4005},
+			Replicas: []yamlServer{{Host: "a", Port: 4001}, {Host: "b\"c", Port: 4002}},
+			Level:    "debug",
+			Ratio:    &ratio,
+			Labels:   map[string]string{"env": "prod", "team": "core"},
+			Ports:    [2]int{80, 443},
+			Extra:    "value",
+		}
+
+		yaml, err := GenerateStructYAML(config)
+		assert.NoError(t, err)
+
+		var decoded yamlConfig
+		assert.NoError(t, a.DecodeYAML(strings.NewReader(yaml), &decoded))
+		assert.Equal(t, config, decoded)
+	})
+
+	t.Run("Adapt Decoded Values", func(t *testing.T) {
+		doc := `
+server:
+  port: 5000
+level: trace
+ratio: 3
+`
+		var decoded yamlConfig
+		assert.NoError(t, a.DecodeYAML(strings.NewReader(doc), &decoded))
+		assert.Equal(t, yamlServer{Host: "localhost", Port: 4010}, decoded.Server)
+		assert.Equal(t, "debug", decoded.Level)
+		assert.Equal(t, 1.0, *decoded.Ratio)
+	})
+
+	t.Run("Empty Document", func(t *testing.T) {
+		var decoded yamlConfig
+		assert.NoError(t, a.DecodeYAML(strings.NewReader(""), &decoded))
+		assert.Equal(t, 4002, decoded.Server.Port)
+	})
+
+	t.Run("Position Of Invalid Value", func(t *testing.T) {
+		doc := "server:\n  host: db\n  port: abc\n"
+
+		var decoded yamlConfig
+		err := a.DecodeYAML(strings.NewReader(doc), &decoded)
+
+		var yerr *YAMLError
+		assert.True(t, errors.As(err, &yerr))
+		assert.Equal(t, "server.port", yerr.Path)
+		assert.Equal(t, 3, yerr.Line)
+		assert.Equal(t, 9, yerr.Column)
+		assert.Equal(t, "yaml: line 3, column 9: field server.port: cannot unmarshal !!str `abc` into int", err.Error())
+	})
+
+	t.Run("Position Of Invalid Node", func(t *testing.T) {
+		doc := "server:\n  - a\n"
+
+		var decoded yamlConfig
+		err := a.DecodeYAML(strings.NewReader(doc), &decoded)
+
+		var yerr *YAMLError
+		assert.True(t, errors.As(err, &yerr))
+		assert.Equal(t, "server", yerr.Path)
+		assert.Equal(t, 2, yerr.Line)
+	})
+
+	t.Run("Invalid Output", func(t *testing.T) {
+		var decoded yamlConfig
+		assert.ErrorIs(t, a.DecodeYAML(strings.NewReader(""), decoded), ErrNotStruct)
+		assert.ErrorIs(t, a.DecodeYAML(strings.NewReader(""), new(int)), ErrNotStruct)
+	})
+}
+
+func Test_DecodeYAML_Time(t *testing.T) {
+	type TimeConfig struct {
+		Timeout time.Duration   `json:"timeout" rst-min:"1s"`
+		Start   time.Time       `json:"start"`
+		Retries []time.Duration `json:"retries" rst-max:"1m"`
+	}
+
+	input := TimeConfig{
+		Timeout: 90 * time.Second,
+		Start:   time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Retries: []time.Duration{time.Second, time.Minute},
+	}
+	generated, err := GenerateStructYAML(input)
+	assert.NoError(t, err)
+
+	var decoded TimeConfig
+	assert.NoError(t, a.DecodeYAML(strings.NewReader(generated), &decoded))
+	assert.Equal(t, input.Timeout, decoded.Timeout)
+	assert.True(t, input.Start.Equal(decoded.Start))
+	assert.Equal(t, input.Retries, decoded.Retries)
+
+	err = a.DecodeYAML(strings.NewReader("timeout: 10ms\nretries: [2h]\n"), &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, decoded.Timeout)
+	assert.Equal(t, []time.Duration{time.Minute}, decoded.Retries)
+}
+
+func Test_LoadYAML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte("server:\n  host: db\n"), 0644))
+
+	var config yamlConfig
+	assert.NoError(t, a.LoadYAML(filename, &config))
+	assert.Equal(t, yamlServer{Host: "db", Port: 4002}, config.Server)
+
+	err := a.LoadYAML(filepath.Join(t.TempDir(), "missing.yaml"), &config)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,413 @@
+// Package adaptgen generates methods applying rst rules of structure
+// types without reflection.
+//
+// For every requested type T it emits
+//
+//	func (c *T) Adapt() error
+//
+// which changes the structure in place as adapt.Adapter.AdaptInPlace
+// with default options does: rst-default, rst-min, rst-max, rst-choice,
+// rst-forbidden and rst-regex are applied in the same order and with
+// the same parsing, nested structures, pointers, slices, arrays and maps
+// are processed recursively, and AdaptFields and ValidateFields methods
+// are called after fields of their structure.
+//
+// Tags which generated code cannot apply, e.g. length rules, rules
+// referencing other fields, custom rules or rules of time.Time fields,
+// are reported as errors, as well as tag values which cannot be parsed.
+// Values stored in interface fields are not processed.
+package adaptgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/amakca/go-adapt/adapt"
+	"github.com/amakca/go-adapt/internal/structtag"
+)
+
+const adaptPath = "github.com/amakca/go-adapt/adapt"
+
+// Config describes the generated file.
+type Config struct {
+	Types  []string // names of structure types getting Adapt method
+	Prefix string   // tag prefix, adapt.RST_PREFIX if empty
+}
+
+// Load loads the package in the directory with syntax and types.
+func Load(dir string) (*packages.Package, error) {
+	// Зависимости проверяются из исходников, без export data тулчейна
+	mode := packages.NeedName | packages.NeedTypes | packages.NeedSyntax |
+		packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: dir}, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages found in %s", len(pkgs), dir)
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, pkg.Errors[0]
+	}
+	return pkg, nil
+}
+
+// Generate returns source of the file with Adapt methods of the types.
+func Generate(pkg *packages.Package, cfg Config) ([]byte, error) {
+	g := &generator{
+		fset:    pkg.Fset,
+		pkg:     pkg.Types,
+		prefix:  cfg.Prefix,
+		imports: make(map[string]string),
+		names:   make(map[string]bool),
+	}
+	if g.prefix == "" {
+		g.prefix = adapt.RST_PREFIX
+	}
+
+	roots := make([]*structFunc, len(cfg.Types))
+	for i, name := range cfg.Types {
+		obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, g.pkg.Path())
+		}
+		if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+			return nil, fmt.Errorf("type %s is not a structure", name)
+		}
+		if isGeneric(obj.Type()) {
+			return nil, fmt.Errorf("generic type %s %w", name, errUnsupported)
+		}
+		fn, err := g.discover(obj.Type(), "")
+		if err != nil {
+			return nil, err
+		}
+		roots[i] = fn
+	}
+	g.resolve()
+
+	for _, fn := range g.funcs {
+		if err := g.emitFunc(fn); err != nil {
+			return nil, err
+		}
+	}
+	return g.file(roots)
+}
+
+type generator struct {
+	fset   *token.FileSet
+	pkg    *types.Package
+	prefix string
+
+	imports map[string]string // import path -> package name
+	names   map[string]bool   // names of generated functions
+
+	types typeutil.Map // structure type -> *structFunc
+	funcs []*structFunc
+
+	regexps []regexVar
+	body    bytes.Buffer
+}
+
+// structFunc is the generated function adapting values of a structure type.
+type structFunc struct {
+	name string
+	t    types.Type
+	st   *types.Struct
+
+	adaptHook    bool // *T has AdaptFields() error
+	validateHook bool // *T has ValidateFields() error
+
+	rules    bool          // some fields have rules
+	regexps  int           // number of regular expressions of fields
+	children []*structFunc // structures of fields
+	work     bool          // the function changes or checks anything
+	fails    bool          // the function may return error
+}
+
+type regexVar struct {
+	name    string
+	pattern string
+}
+
+// discover registers function of the structure type and of structures
+// of its fields. Name of anonymous structures is derived from hint.
+func (g *generator) discover(t types.Type, hint string) (*structFunc, error) {
+	if fn, ok := g.types.At(t).(*structFunc); ok {
+		return fn, nil
+	}
+
+	fn := &structFunc{t: t, st: t.Underlying().(*types.Struct)}
+	if named, ok := t.(*types.Named); ok {
+		hint = named.Obj().Name()
+		if named.Obj().Pkg() != g.pkg {
+			hint = exportName(named.Obj().Pkg().Name()) + hint
+		}
+	}
+	fn.name = g.funcName("adapt" + hint)
+	fn.adaptHook = hasHook(t, adapt.HOOK_ADAPT)
+	fn.validateHook = hasHook(t, adapt.HOOK_VALIDATE)
+	g.types.Set(t, fn)
+	g.funcs = append(g.funcs, fn)
+
+	for i := 0; i < fn.st.NumFields(); i++ {
+		field := fn.st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		if g.hasRules(reflect.StructTag(fn.st.Tag(i))) {
+			fn.rules = true
+		}
+		for _, t := range structsOf(field.Type()) {
+			if isGeneric(t) {
+				return nil, fmt.Errorf("%s: field %s: generic type %s %w", g.position(field), field.Name(), g.typeString(t), errUnsupported)
+			}
+			child, err := g.discover(t, fn.name[len("adapt"):]+field.Name())
+			if err != nil {
+				return nil, err
+			}
+			fn.children = append(fn.children, child)
+		}
+	}
+	return fn, nil
+}
+
+// resolve finds functions which do anything and which may fail,
+// repeating until results are stable, as types may be recursive.
+func (g *generator) resolve() {
+	for _, fn := range g.funcs {
+		fn.work = fn.rules || fn.adaptHook || fn.validateHook
+		fn.fails = fn.adaptHook || fn.validateHook
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range g.funcs {
+			for _, child := range fn.children {
+				if child.work && !fn.work || child.fails && !fn.fails {
+					fn.work = fn.work || child.work
+					fn.fails = fn.fails || child.fails
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// funcName returns unique name of generated function.
+func (g *generator) funcName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// emitFunc writes function applying rules to fields of the structure
+// and calling its hooks.
+func (g *generator) emitFunc(fn *structFunc) error {
+	if !fn.work {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for i := 0; i < fn.st.NumFields(); i++ {
+		field := fn.st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		tag := reflect.StructTag(fn.st.Tag(i))
+		rules, err := g.parseRules(tag)
+		if err == nil {
+			f := fieldGen{g: g, fn: fn, path: structtag.FieldName(field.Name(), tag)}
+			err = f.emitValue(&body, "c."+field.Name(), field.Type(), rules, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: field %s: %w", g.position(field), field.Name(), err)
+		}
+	}
+
+	result := ""
+	if fn.fails {
+		result = " error"
+	}
+	fmt.Fprintf(&g.body, "\nfunc %s(c *%s)%s {\n", fn.name, g.typeString(fn.t), result)
+	g.body.Write(body.Bytes())
+
+	if fn.adaptHook {
+		fmt.Fprintf(&g.body, "if err := c.AdaptFields(); err != nil {\nreturn &adapt.FieldError{Tag: adapt.HOOK_ADAPT, Err: err}\n}\n")
+	}
+	if fn.validateHook {
+		fmt.Fprintf(&g.body, "if err := c.ValidateFields(); err != nil {\nreturn &adapt.FieldError{Tag: adapt.HOOK_VALIDATE, Err: err}\n}\n")
+	}
+	if fn.adaptHook || fn.validateHook {
+		g.imports[adaptPath] = "adapt"
+	}
+	if fn.fails {
+		g.body.WriteString("return nil\n")
+	}
+	g.body.WriteString("}\n")
+	return nil
+}
+
+// file assembles the generated file.
+func (g *generator) file(roots []*structFunc) ([]byte, error) {
+	var methods bytes.Buffer
+	for _, fn := range roots {
+		name := fn.t.(*types.Named).Obj().Name()
+		fmt.Fprintf(&methods, "\n// Adapt applies rules of %s tags without reflection,\n// as adapt.Adapter.AdaptInPlace does.\n", name)
+		fmt.Fprintf(&methods, "func (c *%s) Adapt() error {\n", name)
+		switch {
+		case fn.fails:
+			fmt.Fprintf(&methods, "return %s(c)\n", fn.name)
+		case fn.work:
+			fmt.Fprintf(&methods, "%s(c)\nreturn nil\n", fn.name)
+		default:
+			methods.WriteString("return nil\n")
+		}
+		methods.WriteString("}\n")
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by adaptgen; DO NOT EDIT.\n\npackage %s\n", g.pkg.Name())
+
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		// Пакеты стандартной библиотеки идут отдельной группой
+		sort.SliceStable(paths, func(i, j int) bool {
+			return isStd(paths[i]) && !isStd(paths[j])
+		})
+
+		src.WriteString("\nimport (\n")
+		for i, path := range paths {
+			if i > 0 && isStd(paths[i-1]) != isStd(path) {
+				src.WriteString("\n")
+			}
+			if name := g.imports[path]; name != pathBase(path) {
+				fmt.Fprintf(&src, "%s %q\n", name, path)
+			} else {
+				fmt.Fprintf(&src, "%q\n", path)
+			}
+		}
+		src.WriteString(")\n")
+	}
+
+	src.Write(methods.Bytes())
+	src.Write(g.body.Bytes())
+
+	if len(g.regexps) > 0 {
+		src.WriteString("\nvar (\n")
+		for _, re := range g.regexps {
+			fmt.Fprintf(&src, "%s = regexp.MustCompile(%s)\n", re.name, quote(re.pattern))
+		}
+		src.WriteString(")\n")
+	}
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return out, nil
+}
+
+// typeString returns type expression in the generated file,
+// importing packages of the type.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+func (g *generator) use(path string) {
+	g.imports[path] = pathBase(path)
+}
+
+func (g *generator) position(obj types.Object) token.Position {
+	return g.fset.Position(obj.Pos())
+}
+
+// hasHook reports whether pointer to the type has the method
+// with signature of adapt.Adaptable and adapt.Validatable.
+func hasHook(t types.Type, name string) bool {
+	sel := types.NewMethodSet(types.NewPointer(t)).Lookup(nil, name)
+	if sel == nil {
+		return false
+	}
+	sig := sel.Obj().Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+		types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+// structsOf returns structure types processed as parts of values of type t:
+// the type itself, or structures behind its pointers, slices, arrays and maps.
+func structsOf(t types.Type) []types.Type {
+	if isTime(t) {
+		return nil
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		return []types.Type{t}
+	case *types.Pointer:
+		return structsOf(u.Elem())
+	case *types.Slice:
+		return structsOf(u.Elem())
+	case *types.Array:
+		return structsOf(u.Elem())
+	case *types.Map:
+		return structsOf(u.Elem())
+	default:
+		return nil
+	}
+}
+
+func isGeneric(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && (named.TypeParams().Len() > 0 || named.TypeArgs().Len() > 0)
+}
+
+func isTime(t types.Type) bool {
+	return isNamed(t, "time", "Time")
+}
+
+func isDuration(t types.Type) bool {
+	return isNamed(t, "time", "Duration")
+}
+
+func isNamed(t types.Type, pkg, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkg && named.Obj().Name() == name
+}
+
+// exportName returns the name starting with upper case letter.
+func exportName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// isStd reports whether the import path is of the standard library.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
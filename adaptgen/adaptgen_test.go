@@ -0,0 +1,60 @@
+package adaptgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amakca/go-adapt/adapt"
+)
+
+func Test_Generate(t *testing.T) {
+	t.Run("Up To Date", func(t *testing.T) {
+		dir := filepath.Join("internal", "conformance")
+		pkg, err := Load(dir)
+		require.NoError(t, err)
+
+		src, err := Generate(pkg, Config{Types: []string{"Config"}})
+		require.NoError(t, err)
+
+		// Файл перегенерируется командой go generate ./adaptgen/...
+		committed, err := os.ReadFile(filepath.Join(dir, "config_adapt.go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(committed), string(src))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		pkg, err := Load(filepath.Join("testdata", "invalid"))
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			typeName string
+			prefix   string
+			err      string
+		}{
+			{"Length", "", "invalid.go:6:2: field Name: tag rst-maxlen: not supported by generated code"},
+			{"Reference", "", "field Max: tag rst-min: reference to other field not supported by generated code"},
+			{"Custom", "", "field Name: tag rst-lower: not supported by generated code"},
+			{"Parse", "", `field Port: tag rst-min: strconv.Atoi: parsing "low": invalid syntax`},
+			{"Overflow", "", "field Level: tag rst-max: 300 overflows int8: invalid struct tags"},
+			{"Kind", "", "field Port: tag rst-regex: invalid struct tags"},
+			{"Time", "", "field At: rules of time.Time not supported by generated code"},
+			{"Nested", "", "field Tags: tag rst-forbidden: invalid struct tags"},
+			{"Prefix", "cfg-", "field Port: tag cfg-step: not supported by generated code"},
+			{"Generic", "", "generic type Generic not supported by generated code"},
+			{"Instance", "", "invalid.go:49:2: field Value: generic type Generic[int] not supported by generated code"},
+			{"Missing", "", "type Missing not found"},
+		} {
+			_, err := Generate(pkg, Config{Types: []string{tc.typeName}, Prefix: tc.prefix})
+			if assert.Error(t, err, tc.typeName) {
+				assert.Contains(t, err.Error(), tc.err, tc.typeName)
+			}
+		}
+
+		_, err = Generate(pkg, Config{Types: []string{"Overflow"}})
+		assert.ErrorIs(t, err, adapt.ErrInvalidTags)
+	})
+}
@@ -0,0 +1,75 @@
+// Package conformance checks that code generated by adaptgen
+// adapts values as reflection does.
+package conformance
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+//go:generate go run ../../../cmd/adaptgen -type Config
+
+var errInvalidHost = errors.New("invalid host")
+
+type Level string
+
+type Config struct {
+	Name    string             `json:"name" rst-default:"app" rst-regex:"[^a-z0-9-]"`
+	Port    int                `json:"port" rst-default:"8080" rst-min:"1024" rst-max:"65535"`
+	Workers uint8              `json:"workers" rst-min:"1" rst-max:"64" rst-forbidden:"13||7**12"`
+	Ratio   float64            `json:"ratio" rst-default:"0.5" rst-min:"0.1" rst-max:"0.9"`
+	Scale   float32            `json:"scale" rst-choice:"0.1||0.5||1.5" rst-forbidden:"0.5**1.5"`
+	Level   Level              `json:"level" rst-choice:"info||debug||warn"`
+	Mode    string             `json:"mode" rst-regex:"match:^(fast|safe)$**safe"`
+	Format  string             `json:"format" rst-default:"json" rst-regex:"match:^(json|text)$"`
+	Tag     Level              `json:"tag" rst-regex:"keep:[A-Z]"`
+	Path    string             `json:"path" rst-regex:"replace:/+**/"`
+	Debug   bool               `json:"debug" rst-forbidden:"true**false"`
+	Verbose bool               `json:"verbose" rst-default:"true" rst-choice:"false"`
+	Enabled *bool              `json:"enabled" rst-default:"true"`
+	Limit   *int               `json:"limit" rst-default:"10" rst-max:"100"`
+	Timeout time.Duration      `json:"timeout" rst-default:"30s" rst-min:"1s" rst-max:"1m"`
+	Backoff []time.Duration    `json:"backoff" rst-choice:"1s||2s||5s"`
+	Ports   []int              `json:"ports" rst-min:"1" rst-max:"1000"`
+	Matrix  [][2]int8          `json:"matrix" rst-min:"-9" rst-max:"9"`
+	Weights map[string]float64 `json:"weights" rst-max:"1"`
+	Aliases map[string]*string `json:"aliases" rst-default:"none" rst-regex:"\\s"`
+	Started time.Time          `json:"started"`
+
+	Server   Server            `json:"server"`
+	Backup   *Server           `json:"backup"`
+	Replicas []Server          `json:"replicas"`
+	Zones    map[string]Server `json:"zones"`
+	Limits   struct {
+		CPU    float32 `json:"cpu" rst-min:"0.25" rst-max:"8"`
+		Memory uint64  `json:"memory" rst-default:"512"`
+	} `json:"limits"`
+	Root *Node `json:"root"`
+}
+
+type Server struct {
+	Host string `json:"host" rst-default:"localhost"`
+	Port uint16 `json:"port" rst-min:"1024"`
+	URL  string `json:"url"`
+}
+
+// AdaptFields builds URL from fields already adapted by rules.
+func (s *Server) AdaptFields() error {
+	if s.URL == "" {
+		s.URL = "http://" + s.Host + ":" + strconv.Itoa(int(s.Port))
+	}
+	return nil
+}
+
+func (s Server) ValidateFields() error {
+	if s.Host == "invalid" {
+		return errInvalidHost
+	}
+	return nil
+}
+
+type Node struct {
+	Weight   int     `json:"weight" rst-min:"1"`
+	Children []*Node `json:"children"`
+}
@@ -0,0 +1,219 @@
+// Code generated by adaptgen; DO NOT EDIT.
+
+package conformance
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/amakca/go-adapt/adapt"
+)
+
+// Adapt applies rules of Config tags without reflection,
+// as adapt.Adapter.AdaptInPlace does.
+func (c *Config) Adapt() error {
+	return adaptConfig(c)
+}
+
+func adaptConfig(c *Config) error {
+	if c.Name == "" {
+		c.Name = "app"
+	}
+	c.Name = adaptConfigRegex1.ReplaceAllString(c.Name, "")
+	if c.Port == 0 {
+		c.Port = 8080
+	}
+	if c.Port < 1024 {
+		c.Port = 1024
+	}
+	if c.Port > 65535 {
+		c.Port = 65535
+	}
+	if c.Workers < 1 {
+		c.Workers = 1
+	}
+	if c.Workers > 64 {
+		c.Workers = 64
+	}
+	if c.Workers == 13 || c.Workers == 7 {
+		c.Workers = 12
+	}
+	if c.Ratio == 0 {
+		c.Ratio = 0.5
+	}
+	if c.Ratio < 0.1 {
+		c.Ratio = 0.1
+	}
+	if c.Ratio > 0.9 {
+		c.Ratio = 0.9
+	}
+	if c.Scale != 0 && float64(c.Scale) != 0.1 && float64(c.Scale) != 0.5 && float64(c.Scale) != 1.5 {
+		c.Scale = 0.1
+	}
+	if c.Scale == 0.5 {
+		c.Scale = 1.5
+	}
+	if c.Level != "" && c.Level != "info" && c.Level != "debug" && c.Level != "warn" {
+		c.Level = "info"
+	}
+	if c.Mode != "" && !adaptConfigRegex2.MatchString(c.Mode) {
+		c.Mode = "safe"
+	}
+	if c.Format == "" {
+		c.Format = "json"
+	}
+	if c.Format != "" && !adaptConfigRegex3.MatchString(c.Format) {
+		c.Format = "json"
+	}
+	c.Tag = Level(strings.Join(adaptConfigRegex4.FindAllString(string(c.Tag), -1), ""))
+	c.Path = adaptConfigRegex5.ReplaceAllString(c.Path, "/")
+	if c.Debug {
+		c.Debug = false
+	}
+	if !c.Verbose {
+		c.Verbose = true
+	}
+	if c.Verbose {
+		c.Verbose = false
+	}
+	if c.Enabled == nil {
+		c.Enabled = new(bool)
+		*c.Enabled = true
+	}
+	if c.Limit == nil {
+		c.Limit = new(int)
+		*c.Limit = 10
+	} else {
+		if *c.Limit == 0 {
+			*c.Limit = 10
+		}
+		if *c.Limit > 100 {
+			*c.Limit = 100
+		}
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 30000000000
+	}
+	if c.Timeout < 1000000000 {
+		c.Timeout = 1000000000
+	}
+	if c.Timeout > 60000000000 {
+		c.Timeout = 60000000000
+	}
+	for i0 := range c.Backoff {
+		if c.Backoff[i0] != 0 && c.Backoff[i0] != 1000000000 && c.Backoff[i0] != 2000000000 && c.Backoff[i0] != 5000000000 {
+			c.Backoff[i0] = 1000000000
+		}
+	}
+	for i0 := range c.Ports {
+		if c.Ports[i0] < 1 {
+			c.Ports[i0] = 1
+		}
+		if c.Ports[i0] > 1000 {
+			c.Ports[i0] = 1000
+		}
+	}
+	for i0 := range c.Matrix {
+		for i1 := range c.Matrix[i0] {
+			if c.Matrix[i0][i1] < -9 {
+				c.Matrix[i0][i1] = -9
+			}
+			if c.Matrix[i0][i1] > 9 {
+				c.Matrix[i0][i1] = 9
+			}
+		}
+	}
+	for k0, v0 := range c.Weights {
+		if v0 > 1 {
+			v0 = 1
+		}
+		c.Weights[k0] = v0
+	}
+	for k0, v0 := range c.Aliases {
+		if v0 == nil {
+			v0 = new(string)
+			*v0 = "none"
+		} else {
+			if *v0 == "" {
+				*v0 = "none"
+			}
+			*v0 = adaptConfigRegex6.ReplaceAllString(*v0, "")
+		}
+		c.Aliases[k0] = v0
+	}
+	if err := adaptServer(&c.Server); err != nil {
+		return adapt.NestedError("server", err)
+	}
+	if c.Backup != nil {
+		if err := adaptServer(c.Backup); err != nil {
+			return adapt.NestedError("backup", err)
+		}
+	}
+	for i0 := range c.Replicas {
+		if err := adaptServer(&c.Replicas[i0]); err != nil {
+			return adapt.NestedError("replicas", err)
+		}
+	}
+	for k0, v0 := range c.Zones {
+		if err := adaptServer(&v0); err != nil {
+			return adapt.NestedError("zones", err)
+		}
+		c.Zones[k0] = v0
+	}
+	adaptConfigLimits(&c.Limits)
+	if c.Root != nil {
+		adaptNode(c.Root)
+	}
+	return nil
+}
+
+func adaptServer(c *Server) error {
+	if c.Host == "" {
+		c.Host = "localhost"
+	}
+	if c.Port < 1024 {
+		c.Port = 1024
+	}
+	if err := c.AdaptFields(); err != nil {
+		return &adapt.FieldError{Tag: adapt.HOOK_ADAPT, Err: err}
+	}
+	if err := c.ValidateFields(); err != nil {
+		return &adapt.FieldError{Tag: adapt.HOOK_VALIDATE, Err: err}
+	}
+	return nil
+}
+
+func adaptConfigLimits(c *struct {
+	CPU    float32 "json:\"cpu\" rst-min:\"0.25\" rst-max:\"8\""
+	Memory uint64  "json:\"memory\" rst-default:\"512\""
+}) {
+	if c.CPU < 0.25 {
+		c.CPU = 0.25
+	}
+	if c.CPU > 8 {
+		c.CPU = 8
+	}
+	if c.Memory == 0 {
+		c.Memory = 512
+	}
+}
+
+func adaptNode(c *Node) {
+	if c.Weight < 1 {
+		c.Weight = 1
+	}
+	for i0 := range c.Children {
+		if c.Children[i0] != nil {
+			adaptNode(c.Children[i0])
+		}
+	}
+}
+
+var (
+	adaptConfigRegex1 = regexp.MustCompile(`[^a-z0-9-]`)
+	adaptConfigRegex2 = regexp.MustCompile(`^(fast|safe)$`)
+	adaptConfigRegex3 = regexp.MustCompile(`^(json|text)$`)
+	adaptConfigRegex4 = regexp.MustCompile(`[A-Z]`)
+	adaptConfigRegex5 = regexp.MustCompile(`/+`)
+	adaptConfigRegex6 = regexp.MustCompile(`\s`)
+)
@@ -0,0 +1,141 @@
+package conformance
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amakca/go-adapt/adapt"
+)
+
+func Test_Conformance(t *testing.T) {
+	a := adapt.New(adapt.WithLogger(nil))
+
+	for seed := int64(0); seed < 500; seed++ {
+		// Одинаковые значения для обоих путей строятся из одного seed
+		generated := randomConfig(rand.New(rand.NewSource(seed)))
+		reflective := randomConfig(rand.New(rand.NewSource(seed)))
+
+		genErr := generated.Adapt()
+		_, refErr := a.AdaptInPlace(&reflective)
+
+		if !assert.Equal(t, reflective, generated, "seed %d", seed) {
+			return
+		}
+		if refErr != nil {
+			assert.EqualError(t, genErr, refErr.Error(), "seed %d", seed)
+		} else {
+			assert.NoError(t, genErr, "seed %d", seed)
+		}
+	}
+}
+
+func Test_ConformanceErrors(t *testing.T) {
+	config := Config{Replicas: []Server{{}, {Host: "invalid"}}}
+	err := config.Adapt()
+
+	var ferr *adapt.FieldError
+	if assert.ErrorAs(t, err, &ferr) {
+		assert.Equal(t, "replicas", ferr.Path)
+		assert.Equal(t, adapt.HOOK_VALIDATE, ferr.Tag)
+	}
+	assert.ErrorIs(t, err, errInvalidHost)
+	assert.EqualError(t, err, "field replicas, ValidateFields: invalid host")
+}
+
+func randomConfig(r *rand.Rand) Config {
+	config := Config{
+		Name:    pick(r, "", "app", "My App!", "svc-1"),
+		Port:    pick(r, 0, -1, 80, 1024, 8080, 70000),
+		Workers: pick[uint8](r, 0, 1, 7, 13, 64, 200),
+		Ratio:   pick(r, 0, math.Copysign(0, -1), 0.05, 0.1, 0.5, 0.95, -3),
+		Scale:   pick[float32](r, 0, 0.1, 0.5, 1.5, 2),
+		Level:   pick[Level](r, "", "info", "debug", "trace"),
+		Mode:    pick(r, "", "fast", "safe", "unsafe"),
+		Format:  pick(r, "", "json", "text", "xml"),
+		Tag:     pick[Level](r, "", "AbC", "abc"),
+		Path:    pick(r, "", "/a//b", "///"),
+		Debug:   pick(r, false, true),
+		Verbose: pick(r, false, true),
+		Timeout: pick(r, 0, time.Millisecond, 5*time.Second, time.Hour),
+		Started: pick(r, time.Time{}, time.Unix(0, 0).UTC()),
+		Server:  randomServer(r),
+	}
+
+	if r.Intn(2) == 0 {
+		config.Enabled = pick(r, new(bool), ptr(true))
+	}
+	if r.Intn(2) == 0 {
+		config.Limit = ptr(pick(r, 0, 50, 500))
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		config.Backoff = append(config.Backoff, pick(r, 0, time.Second, 3*time.Second, 5*time.Second))
+		config.Ports = append(config.Ports, pick(r, -5, 0, 100, 5000))
+		config.Matrix = append(config.Matrix, [2]int8{pick[int8](r, -100, 0, 5), pick[int8](r, 10, -9, 127)})
+	}
+	if r.Intn(3) > 0 {
+		config.Weights = map[string]float64{"a": pick(r, 0, 0.5, 2), "b": pick(r, 1.0, 3)}
+		config.Aliases = map[string]*string{"a": nil, "b": ptr(pick(r, "", "x y", "z"))}
+	}
+
+	if r.Intn(2) == 0 {
+		backup := randomServer(r)
+		config.Backup = &backup
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		config.Replicas = append(config.Replicas, randomServer(r))
+	}
+	if r.Intn(2) == 0 {
+		config.Zones = map[string]Server{"eu": randomServer(r)}
+	}
+
+	config.Limits.CPU = pick[float32](r, 0, 0.1, 1, 16)
+	config.Limits.Memory = pick[uint64](r, 0, 1024)
+	if r.Intn(2) == 0 {
+		config.Root = &Node{Weight: pick(r, -1, 5), Children: []*Node{nil, {Weight: pick(r, 0, 3)}}}
+	}
+	return config
+}
+
+func randomServer(r *rand.Rand) Server {
+	return Server{
+		Host: pick(r, "", "localhost", "example.com", "invalid"),
+		Port: pick[uint16](r, 0, 80, 8080),
+		URL:  pick(r, "", "http://fixed"),
+	}
+}
+
+func pick[T any](r *rand.Rand, values ...T) T {
+	return values[r.Intn(len(values))]
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func BenchmarkAdapt(b *testing.B) {
+	a := adapt.New(adapt.WithLogger(nil))
+	config := randomConfig(rand.New(rand.NewSource(1)))
+	config.Server.Host = "localhost"
+
+	b.Run("Generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := config.Adapt(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := a.AdaptInPlace(&config); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
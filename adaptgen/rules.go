@@ -0,0 +1,623 @@
+package adaptgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amakca/go-adapt/adapt"
+	"github.com/amakca/go-adapt/internal/structtag"
+)
+
+var errUnsupported = errors.New("not supported by generated code")
+
+// unsupportedRules are built-in rules generated code does not apply.
+var unsupportedRules = []string{adapt.RST_MINLEN, adapt.RST_MAXLEN}
+
+// ruleSet holds values of rules of a field under canonical names.
+type ruleSet map[string]string
+
+// without returns rules without the rule.
+func (rs ruleSet) without(name string) ruleSet {
+	if _, ok := rs[name]; !ok {
+		return rs
+	}
+	rules := make(ruleSet, len(rs))
+	for n, v := range rs {
+		if n != name {
+			rules[n] = v
+		}
+	}
+	return rules
+}
+
+// hasRules reports whether the tag has keys with the prefix.
+func (g *generator) hasRules(tag reflect.StructTag) bool {
+	for _, key := range structtag.Keys(tag) {
+		if strings.HasPrefix(key, g.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRules returns rules of the tag, or nil if there are none.
+// Rules which generated code cannot apply are reported as errors.
+func (g *generator) parseRules(tag reflect.StructTag) (ruleSet, error) {
+	var rules ruleSet
+	for _, key := range structtag.Keys(tag) {
+		rest, ok := strings.CutPrefix(key, g.prefix)
+		if !ok {
+			continue
+		}
+		name := adapt.RST_PREFIX + rest
+
+		if !slices.Contains(structtag.RulesOrder, name) || slices.Contains(unsupportedRules, name) {
+			return nil, fmt.Errorf("tag %s: %w", key, errUnsupported)
+		}
+
+		// Пустые значения не читаются и адаптером
+		value := tag.Get(key)
+		if value == "" {
+			continue
+		}
+		if (name == adapt.RST_MIN || name == adapt.RST_MAX) && strings.HasPrefix(value, adapt.REF_PREFIX) {
+			return nil, fmt.Errorf("tag %s: reference to other field %w", key, errUnsupported)
+		}
+
+		if rules == nil {
+			rules = make(ruleSet)
+		}
+		rules[name] = value
+	}
+	return rules, nil
+}
+
+// fieldGen writes code applying rules of a single field.
+type fieldGen struct {
+	g    *generator
+	fn   *structFunc
+	path string // name of the field in paths of errors
+}
+
+// emitValue writes code processing value of expression expr of type t
+// as adapt.Adapter.processField does. Loop variables are numbered by depth.
+func (f *fieldGen) emitValue(w *bytes.Buffer, expr string, t types.Type, rules ruleSet, depth int) error {
+	if isTime(t) {
+		if rules != nil {
+			return fmt.Errorf("rules of time.Time %w", errUnsupported)
+		}
+		return nil
+	}
+	if isDuration(t) {
+		return f.emitRules(w, expr, t, rules)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		if rules != nil {
+			return fmt.Errorf("rules of structure: %w", adapt.ErrInvalidTags)
+		}
+		f.emitStruct(w, expr, t)
+
+	case *types.Pointer:
+		return f.emitPointer(w, expr, u.Elem(), rules, depth)
+
+	case *types.Slice:
+		return f.emitElements(w, expr, u.Elem(), rules, depth)
+
+	case *types.Array:
+		return f.emitElements(w, expr, u.Elem(), rules, depth)
+
+	case *types.Map:
+		var elem bytes.Buffer
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		if err := f.emitValue(&elem, v, u.Elem(), rules, depth+1); err != nil {
+			return err
+		}
+		if elem.Len() > 0 {
+			// Значения карты не адресуемы: обрабатываем копию и записываем ее обратно
+			fmt.Fprintf(w, "for %s, %s := range %s {\n%s%s[%s] = %s\n}\n", k, v, expr, elem.Bytes(), operand(expr), k, v)
+		}
+
+	case *types.Interface:
+		if rules != nil {
+			return fmt.Errorf("rules of interface %w", errUnsupported)
+		}
+
+	default:
+		return f.emitRules(w, expr, t, rules)
+
+	}
+	return nil
+}
+
+// emitStruct writes call of the function of the structure type.
+func (f *fieldGen) emitStruct(w *bytes.Buffer, expr string, t types.Type) {
+	fn := f.g.types.At(t).(*structFunc)
+	if !fn.work {
+		return
+	}
+
+	ptr := "&" + expr
+	if inner, ok := strings.CutPrefix(expr, "*"); ok {
+		ptr = inner
+	}
+	if fn.fails {
+		fmt.Fprintf(w, "if err := %s(%s); err != nil {\nreturn adapt.NestedError(%q, err)\n}\n", fn.name, ptr, f.path)
+		f.g.use(adaptPath)
+	} else {
+		fmt.Fprintf(w, "%s(%s)\n", fn.name, ptr)
+	}
+}
+
+// emitPointer writes code of pointer: nil pointer is set to new value
+// with rst-default, rules of the value behind pointer are applied to it.
+// Default is not applied to bool explicitly set through pointer.
+func (f *fieldGen) emitPointer(w *bytes.Buffer, expr string, elem types.Type, rules ruleSet, depth int) error {
+	var nilCode bytes.Buffer
+	if def, ok := rules[adapt.RST_DEFAULT]; ok {
+		if !isSimple(elem) {
+			return fmt.Errorf("tag %s: %w", adapt.RST_DEFAULT, adapt.ErrInvalidTags)
+		}
+		lit, err := f.literal(elem, def, 64)
+		if err != nil {
+			return fmt.Errorf("tag %s: %w", adapt.RST_DEFAULT, err)
+		}
+		fmt.Fprintf(&nilCode, "%s = new(%s)\n*%s = %s\n", expr, f.g.typeString(elem), expr, lit)
+	}
+
+	if basic, ok := elem.Underlying().(*types.Basic); ok && basic.Info()&types.IsBoolean != 0 {
+		rules = rules.without(adapt.RST_DEFAULT)
+	}
+	var code bytes.Buffer
+	if err := f.emitValue(&code, "*"+expr, elem, rules, depth); err != nil {
+		return err
+	}
+
+	switch {
+	case nilCode.Len() > 0 && code.Len() > 0:
+		fmt.Fprintf(w, "if %s == nil {\n%s} else {\n%s}\n", expr, nilCode.Bytes(), code.Bytes())
+	case nilCode.Len() > 0:
+		fmt.Fprintf(w, "if %s == nil {\n%s}\n", expr, nilCode.Bytes())
+	case code.Len() > 0:
+		fmt.Fprintf(w, "if %s != nil {\n%s}\n", expr, code.Bytes())
+	}
+	return nil
+}
+
+// emitElements writes loop over elements of slice or array.
+func (f *fieldGen) emitElements(w *bytes.Buffer, expr string, elem types.Type, rules ruleSet, depth int) error {
+	var code bytes.Buffer
+	i := fmt.Sprintf("i%d", depth)
+	if err := f.emitValue(&code, operand(expr)+"["+i+"]", elem, rules, depth+1); err != nil {
+		return err
+	}
+	if code.Len() > 0 {
+		fmt.Fprintf(w, "for %s := range %s {\n%s}\n", i, expr, code.Bytes())
+	}
+	return nil
+}
+
+// operand returns expression usable as operand of index expression.
+func operand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// emitRules writes rules of simple value in order they are applied.
+func (f *fieldGen) emitRules(w *bytes.Buffer, expr string, t types.Type, rules ruleSet) error {
+	if rules == nil {
+		return nil
+	}
+	if !isSimple(t) {
+		return fmt.Errorf("rules of %s %w", t, errUnsupported)
+	}
+	for _, name := range structtag.RulesOrder {
+		if value, ok := rules[name]; ok {
+			if err := f.emitRule(w, expr, t, name, value, rules); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// emitRule writes code of the rule, parsing its value as the rule of adapt does.
+func (f *fieldGen) emitRule(w *bytes.Buffer, expr string, t types.Type, name, value string, rules ruleSet) error {
+	var err error
+	switch name {
+	case adapt.RST_DEFAULT:
+		err = f.emitDefault(w, expr, t, value)
+	case adapt.RST_MIN:
+		err = f.emitBound(w, expr, t, value, "<")
+	case adapt.RST_MAX:
+		err = f.emitBound(w, expr, t, value, ">")
+	case adapt.RST_CHOICE:
+		err = f.emitChoice(w, expr, t, value)
+	case adapt.RST_FORBIDDEN:
+		err = f.emitForbidden(w, expr, t, value)
+	case adapt.RST_REGEX:
+		err = f.emitRegex(w, expr, t, value, rules)
+	}
+	if err != nil {
+		return fmt.Errorf("tag %s: %w", name, err)
+	}
+	return nil
+}
+
+// emitDefault sets zero value to the default.
+func (f *fieldGen) emitDefault(w *bytes.Buffer, expr string, t types.Type, value string) error {
+	kind := kindOf(t)
+	if kind == kindBool {
+		def, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if def {
+			fmt.Fprintf(w, "if !%s {\n%s = true\n}\n", expr, expr)
+		}
+		return nil
+	}
+
+	def, err := f.literal(t, value, 64)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "if %s {\n%s = %s\n}\n", f.isZero(expr, t), expr, def)
+	return nil
+}
+
+// emitBound writes rst-min with operator "<" or rst-max with ">".
+func (f *fieldGen) emitBound(w *bytes.Buffer, expr string, t types.Type, value, op string) error {
+	switch kindOf(t) {
+	case kindString, kindBool:
+		return adapt.ErrInvalidTags
+	}
+
+	// Границы float32 разбираются с точностью поля, как в adapt
+	bound, err := f.literal(t, value, 32)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "if %s %s %s {\n%s = %s\n}\n", expr, op, bound, expr, bound)
+	return nil
+}
+
+// emitChoice replaces nonzero value missing from the set with the first option.
+func (f *fieldGen) emitChoice(w *bytes.Buffer, expr string, t types.Type, value string) error {
+	options := strings.Split(value, adapt.SET_DELIMITER)
+	kind := kindOf(t)
+
+	if kind == kindBool {
+		values, err := parseBools(options)
+		if err != nil {
+			return err
+		}
+		if !contains(values, "true") {
+			fmt.Fprintf(w, "if %s {\n%s = %s\n}\n", expr, expr, values[0])
+		}
+		return nil
+	}
+
+	// Дробные значения сравниваются в float64, как в adapt
+	cmp := expr
+	if kind == kindFloat32 {
+		cmp = "float64(" + expr + ")"
+	}
+
+	conds := []string{f.isNonZero(expr, t)}
+	var first string
+	for i, option := range options {
+		lit, err := f.literal(t, option, 64)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			first = lit
+		}
+		if kind == kindFloat32 {
+			lit, _ = f.floatLiteral(option, 64, 64)
+		}
+		conds = appendUnique(conds, cmp+" != "+lit)
+	}
+	fmt.Fprintf(w, "if %s {\n%s = %s\n}\n", strings.Join(conds, " && "), expr, first)
+	return nil
+}
+
+// emitForbidden replaces forbidden value with the value following VAL_DELIMITER.
+func (f *fieldGen) emitForbidden(w *bytes.Buffer, expr string, t types.Type, value string) error {
+	options := strings.Split(value, adapt.SET_DELIMITER)
+	withDef := strings.Split(options[len(options)-1], adapt.VAL_DELIMITER)
+	if len(withDef) != 2 {
+		return adapt.ErrInvalidTags
+	}
+	options = append(options[:len(options)-1], withDef...)
+	forbidden, replacement := options[:len(options)-1], options[len(options)-1]
+
+	if kindOf(t) == kindBool {
+		values, err := parseBools(options)
+		if err != nil {
+			return err
+		}
+		repl := values[len(values)-1]
+		switch values = values[:len(values)-1]; {
+		case contains(values, "true") && contains(values, "false"):
+			fmt.Fprintf(w, "%s = %s\n", expr, repl)
+		case contains(values, "true"):
+			fmt.Fprintf(w, "if %s {\n%s = %s\n}\n", expr, expr, repl)
+		default:
+			fmt.Fprintf(w, "if !%s {\n%s = %s\n}\n", expr, expr, repl)
+		}
+		return nil
+	}
+
+	// Значения float32 разбираются с точностью поля, как в adapt
+	var conds []string
+	for _, option := range forbidden {
+		lit, err := f.literal(t, option, 32)
+		if err != nil {
+			return err
+		}
+		conds = appendUnique(conds, expr+" == "+lit)
+	}
+	repl, err := f.literal(t, replacement, 32)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "if %s {\n%s = %s\n}\n", strings.Join(conds, " || "), expr, repl)
+	return nil
+}
+
+// emitRegex writes rule of the rst-regex mode with expression compiled
+// once into a package variable.
+func (f *fieldGen) emitRegex(w *bytes.Buffer, expr string, t types.Type, value string, rules ruleSet) error {
+	if kindOf(t) != kindString {
+		return adapt.ErrInvalidTags
+	}
+
+	mode, pattern, arg, hasArg := structtag.ParseRegex(value)
+	if _, err := regexp.Compile(pattern); err != nil {
+		return err
+	}
+	re := f.regexVar(pattern)
+
+	// Значения именованных строковых типов приводятся к string и обратно
+	str, conv := expr, func(s string) string { return s }
+	if _, ok := t.(*types.Basic); !ok {
+		typ := f.g.typeString(t)
+		str, conv = "string("+expr+")", func(s string) string { return typ + "(" + s + ")" }
+	}
+
+	switch mode {
+	case adapt.REGEX_KEEP:
+		f.g.use("strings")
+		fmt.Fprintf(w, "%s = %s\n", expr, conv(fmt.Sprintf(`strings.Join(%s.FindAllString(%s, -1), "")`, re, str)))
+
+	case adapt.REGEX_MATCH:
+		if !hasArg {
+			def, ok := rules[adapt.RST_DEFAULT]
+			if !ok {
+				return fmt.Errorf("no fallback and no default: %w", adapt.ErrInvalidTags)
+			}
+			arg = def
+		}
+		fmt.Fprintf(w, "if %s != \"\" && !%s.MatchString(%s) {\n%s = %s\n}\n", expr, re, str, expr, strconv.Quote(arg))
+
+	case adapt.REGEX_REPLACE:
+		if !hasArg {
+			return fmt.Errorf("no replacement: %w", adapt.ErrInvalidTags)
+		}
+		fmt.Fprintf(w, "%s = %s\n", expr, conv(fmt.Sprintf("%s.ReplaceAllString(%s, %s)", re, str, strconv.Quote(arg))))
+
+	default:
+		fmt.Fprintf(w, "%s = %s\n", expr, conv(fmt.Sprintf(`%s.ReplaceAllString(%s, "")`, re, str)))
+
+	}
+	return nil
+}
+
+// regexVar returns name of package variable with the compiled expression.
+func (f *fieldGen) regexVar(pattern string) string {
+	f.g.use("regexp")
+	for _, re := range f.g.regexps {
+		if re.pattern == pattern {
+			return re.name
+		}
+	}
+	f.fn.regexps++
+	name := f.g.funcName(fmt.Sprintf("%sRegex%d", f.fn.name, f.fn.regexps))
+	f.g.regexps = append(f.g.regexps, regexVar{name: name, pattern: pattern})
+	return name
+}
+
+// isZero returns condition of zero value.
+func (f *fieldGen) isZero(expr string, t types.Type) string {
+	if kindOf(t) == kindString {
+		return expr + ` == ""`
+	}
+	return expr + " == 0"
+}
+
+// isNonZero returns negation of isZero.
+func (f *fieldGen) isNonZero(expr string, t types.Type) string {
+	if kindOf(t) == kindString {
+		return expr + ` != ""`
+	}
+	return expr + " != 0"
+}
+
+// literal returns constant of the value for the field of type t.
+// Values of float32 fields are parsed with bitSize, as each rule of adapt
+// does, and rounded to float32.
+func (f *fieldGen) literal(t types.Type, value string, bitSize int) (string, error) {
+	switch kind := kindOf(t); kind {
+	case kindDuration:
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(int64(d), 10), nil
+
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", err
+		}
+		if size := sizeOf(t); size < 64 && (n < -1<<(size-1) || n >= 1<<(size-1)) {
+			return "", fmt.Errorf("%d overflows %s: %w", n, t, adapt.ErrInvalidTags)
+		}
+		return strconv.Itoa(n), nil
+
+	case kindUint:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if size := sizeOf(t); size < 64 && n >= 1<<size {
+			return "", fmt.Errorf("%d overflows %s: %w", n, t, adapt.ErrInvalidTags)
+		}
+		return strconv.FormatUint(n, 10), nil
+
+	case kindFloat32:
+		return f.floatLiteral(value, bitSize, 32)
+
+	case kindFloat64:
+		return f.floatLiteral(value, 64, 64)
+
+	case kindString:
+		return strconv.Quote(value), nil
+
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+
+	default:
+		return "", fmt.Errorf("values of %s: %w", t, adapt.ErrInvalidTags)
+
+	}
+}
+
+// floatLiteral parses the value with bitSize and formats it
+// with precision of the field.
+func (f *fieldGen) floatLiteral(value string, bitSize, fieldSize int) (string, error) {
+	x, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		return "", err
+	}
+	if fieldSize == 32 {
+		x = float64(float32(x))
+	}
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return "", fmt.Errorf("%s is not finite: %w", value, errUnsupported)
+	}
+	return strconv.FormatFloat(x, 'g', -1, fieldSize), nil
+}
+
+type valueKind int
+
+const (
+	kindOther valueKind = iota
+	kindInt
+	kindUint
+	kindFloat32
+	kindFloat64
+	kindString
+	kindBool
+	kindDuration
+)
+
+// kindOf returns kind of values rules are applied to, as adapt
+// selects rules by reflect.Kind.
+func kindOf(t types.Type) valueKind {
+	if isDuration(t) {
+		return kindDuration
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return kindOther
+	}
+	switch basic.Kind() {
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		return kindInt
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return kindUint
+	case types.Float32:
+		return kindFloat32
+	case types.Float64:
+		return kindFloat64
+	case types.String:
+		return kindString
+	case types.Bool:
+		return kindBool
+	default:
+		return kindOther
+	}
+}
+
+func isSimple(t types.Type) bool {
+	return kindOf(t) != kindOther
+}
+
+// sizeOf returns size of integer type in bits, int and uint are 64 bits wide.
+func sizeOf(t types.Type) int {
+	switch t.Underlying().(*types.Basic).Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+func parseBools(options []string) ([]string, error) {
+	values := make([]string, len(options))
+	for i, option := range options {
+		b, err := strconv.ParseBool(option)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = strconv.FormatBool(b)
+	}
+	return values, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// quote returns raw string literal of the value if possible.
+func quote(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,50 @@
+package invalid
+
+import "time"
+
+type Length struct {
+	Name string `rst-maxlen:"10"`
+}
+
+type Reference struct {
+	Min int `json:"min"`
+	Max int `rst-min:"@min"`
+}
+
+type Custom struct {
+	Name string `rst-lower:"true"`
+}
+
+type Parse struct {
+	Port int `rst-min:"low"`
+}
+
+type Overflow struct {
+	Level int8 `rst-max:"300"`
+}
+
+type Kind struct {
+	Port int `rst-regex:"[0-9]"`
+}
+
+type Time struct {
+	At time.Time `rst-min:"now"`
+}
+
+type Nested struct {
+	Inner struct {
+		Tags []string `rst-forbidden:"a"`
+	}
+}
+
+type Prefix struct {
+	Port int `cfg-min:"1" cfg-step:"2"`
+}
+
+type Generic[T any] struct {
+	Value T
+}
+
+type Instance struct {
+	Value Generic[int]
+}
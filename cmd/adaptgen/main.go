@@ -0,0 +1,62 @@
+// Command adaptgen generates Adapt methods applying rst rules
+// of structure types without reflection.
+//
+// Usage:
+//
+//	//go:generate go run github.com/amakca/go-adapt/cmd/adaptgen -type Config
+//
+// The file <type>_adapt.go is written to the package directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amakca/go-adapt/adapt"
+	"github.com/amakca/go-adapt/adaptgen"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated names of structure types")
+	output := flag.String("output", "", "output file name, <type>_adapt.go by default")
+	prefix := flag.String("prefix", adapt.RST_PREFIX, "prefix of rule tags")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: adaptgen -type T[,T...] [-output file] [-prefix rst-] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, strings.Split(*typeNames, ","), *output, *prefix); err != nil {
+		fmt.Fprintf(os.Stderr, "adaptgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, typeNames []string, output, prefix string) error {
+	pkg, err := adaptgen.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	src, err := adaptgen.Generate(pkg, adaptgen.Config{Types: typeNames, Prefix: prefix})
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.ToLower(typeNames[0]) + "_adapt.go"
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
@@ -0,0 +1,16 @@
+// Command rstcheck checks rst tags of structure fields.
+//
+// It runs standalone on packages or as a vet tool:
+//
+//	go vet -vettool=$(which rstcheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/amakca/go-adapt/rstcheck"
+)
+
+func main() {
+	singlechecker.Main(rstcheck.Analyzer)
+}
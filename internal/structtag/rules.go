@@ -0,0 +1,51 @@
+package structtag
+
+import "strings"
+
+// Canonical names of built-in rules, exported by adapt as RST_* constants.
+const (
+	RuleDefault   = "rst-default"
+	RuleMin       = "rst-min"
+	RuleMax       = "rst-max"
+	RuleChoice    = "rst-choice"
+	RuleForbidden = "rst-forbidden"
+	RuleRegex     = "rst-regex"
+	RuleMinLen    = "rst-minlen"
+	RuleMaxLen    = "rst-maxlen"
+)
+
+// RulesOrder is the order in which built-in rules are applied.
+// Length rules go last, so the limits hold for the final value.
+var RulesOrder = []string{RuleDefault, RuleMin, RuleMax, RuleChoice, RuleForbidden, RuleRegex, RuleMinLen, RuleMaxLen}
+
+// ValDelimiter separates argument of a rule from its value.
+const ValDelimiter = "**"
+
+// Modes of rst-regex selected by prefix of the tag value.
+const (
+	RegexStrip   = "strip:"
+	RegexKeep    = "keep:"
+	RegexMatch   = "match:"
+	RegexReplace = "replace:"
+)
+
+// RegexModes lists prefixes of rst-regex modes.
+var RegexModes = []string{RegexStrip, RegexKeep, RegexMatch, RegexReplace}
+
+// ParseRegex splits value of rst-regex into mode, pattern and argument
+// of the mode following ValDelimiter. Value without a known prefix
+// is a pattern of RegexStrip mode.
+func ParseRegex(value string) (mode, pattern, arg string, hasArg bool) {
+	mode, pattern = RegexStrip, value
+	for _, m := range RegexModes {
+		if rest, ok := strings.CutPrefix(pattern, m); ok {
+			mode, pattern = m, rest
+			break
+		}
+	}
+
+	if mode == RegexMatch || mode == RegexReplace {
+		pattern, arg, hasArg = strings.Cut(pattern, ValDelimiter)
+	}
+	return mode, pattern, arg, hasArg
+}
@@ -0,0 +1,60 @@
+// Package structtag holds syntax of structure tags shared by the adapt
+// package and the adaptgen generator: keys and field names, order of
+// built-in rules and modes of rst-regex, so generated code applies
+// rules as adapt does.
+package structtag
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Keys returns keys of the structure tag in order,
+// parsing it the same way as reflect.StructTag.Lookup.
+func Keys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FieldName returns name of the field used in dotted paths by default:
+// name from json tag if present, otherwise name of the field.
+func FieldName(name string, tag reflect.StructTag) string {
+	jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+	if jsonName != "" && jsonName != "-" {
+		return jsonName
+	}
+	return name
+}
@@ -0,0 +1,40 @@
+package structtag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Keys(t *testing.T) {
+	assert.Equal(t, []string{"json", "rst-min", "info"}, Keys(`json:"a" rst-min:"1"  info:"a \"b\""`))
+	assert.Equal(t, []string{"json"}, Keys(`json:"a" broken`))
+	assert.Nil(t, Keys(""))
+}
+
+func Test_FieldName(t *testing.T) {
+	for tag, name := range map[reflect.StructTag]string{
+		`json:"port"`:           "port",
+		`json:"port,omitempty"`: "port",
+		`json:",omitempty"`:     "Port",
+		`json:"-"`:              "Port",
+		`yaml:"port"`:           "Port",
+	} {
+		assert.Equal(t, name, FieldName("Port", tag), tag)
+	}
+}
+
+func Test_ParseRegex(t *testing.T) {
+	for value, want := range map[string][4]any{
+		"[ ]":                {RegexStrip, "[ ]", "", false},
+		"strip:a**b":         {RegexStrip, "a**b", "", false},
+		"keep:[a-z]":         {RegexKeep, "[a-z]", "", false},
+		"match:^a$":          {RegexMatch, "^a$", "", false},
+		"match:^a$**b":       {RegexMatch, "^a$", "b", true},
+		"replace:(a)**${1}b": {RegexReplace, "(a)", "${1}b", true},
+	} {
+		mode, pattern, arg, hasArg := ParseRegex(value)
+		assert.Equal(t, want, [4]any{mode, pattern, arg, hasArg}, value)
+	}
+}
@@ -0,0 +1,246 @@
+// Package rstcheck defines an analyzer checking rst tags of structure
+// fields in source code, as adapt.CheckField does at run time.
+//
+// The analyzer reports tag values which cannot be parsed for the type
+// of the field, rules on types they are not applied to, contradictory
+// rules (rst-min greater than rst-max, rst-default outside of them)
+// and unknown rules with the tag prefix. References to other fields
+// are checked only for syntax, as they depend on the adapted structure.
+package rstcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/amakca/go-adapt/adapt"
+)
+
+const doc = `check rst tags of structure fields
+
+The rstcheck analyzer reports invalid values of rst tags, rules on
+field types they are not applied to, contradictory rules and unknown
+rules with the tag prefix.`
+
+// Analyzer checks rst tags of structure fields.
+var Analyzer = &analysis.Analyzer{
+	Name:     "rstcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	prefix string // tag prefix, as set by adapt.WithTagPrefix
+	rules  string // comma separated names of custom rules
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&prefix, "prefix", adapt.RST_PREFIX, "prefix of rule tags")
+	Analyzer.Flags.StringVar(&rules, "rules", "", "comma separated names of custom rules")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	a, err := newAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, field := range n.(*ast.StructType).Fields.List {
+			checkField(pass, a, field)
+		}
+	})
+	return nil, nil
+}
+
+// newAdapter creates adapter with the tag prefix and custom rules of flags.
+func newAdapter() (*adapt.Adapter, error) {
+	opts := []adapt.Option{adapt.WithLogger(nil), adapt.WithTagPrefix(prefix)}
+	for _, name := range strings.Split(rules, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts = append(opts, adapt.WithRule(name, func(string, reflect.Value, string) error { return nil }))
+		}
+	}
+	return adapt.NewE(opts...)
+}
+
+func checkField(pass *analysis.Pass, a *adapt.Adapter, field *ast.Field) {
+	if field.Tag == nil {
+		return
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil || !strings.Contains(raw, prefix) {
+		return
+	}
+
+	// Правила применяются только к экспортируемым полям
+	exported := len(field.Names) == 0
+	for _, name := range field.Names {
+		exported = exported || name.IsExported()
+	}
+	if !exported {
+		return
+	}
+
+	t := reflectType(pass.TypesInfo.TypeOf(field.Type), make(map[types.Type]bool))
+	if t == nil {
+		return
+	}
+
+	err = a.CheckField(t, reflect.StructTag(raw))
+	ferrs, ok := err.(*adapt.FieldErrors)
+	if !ok {
+		return
+	}
+	for _, ferr := range ferrs.Errors {
+		key := tagKey(ferr.Tag)
+		pass.Reportf(field.Tag.Pos()+tagOffset(field.Tag.Value, key), "tag %s: %v", key, ferr.Err)
+	}
+}
+
+// tagKey returns key of the rule in tags with the prefix,
+// errors report built-in rules under their canonical names.
+func tagKey(name string) string {
+	if rest, ok := strings.CutPrefix(name, adapt.RST_PREFIX); ok {
+		return prefix + rest
+	}
+	return name
+}
+
+// tagOffset returns offset of the key in the tag literal,
+// or 0 if the key is not found.
+func tagOffset(literal, key string) token.Pos {
+	for i := 0; ; {
+		j := strings.Index(literal[i:], key+":")
+		if j < 0 {
+			return 0
+		}
+		i += j
+		if i > 0 && (literal[i-1] == ' ' || literal[i-1] == '`' || literal[i-1] == '"') {
+			return token.Pos(i)
+		}
+		i += len(key)
+	}
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	structType   = reflect.TypeOf(struct{}{})
+	anyType      = reflect.TypeOf((*any)(nil)).Elem()
+)
+
+// reflectType returns reflect type with the same kinds as the type in source,
+// which is enough for rules depending only on kinds. Structures are replaced
+// with empty ones, as their fields are checked separately. It returns nil
+// for types which cannot be represented.
+func reflectType(t types.Type, seen map[types.Type]bool) reflect.Type {
+	if t == nil || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" {
+		switch named.Obj().Name() {
+		case "Duration":
+			return durationType
+		case "Time":
+			return timeType
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return basicType(u.Kind())
+
+	case *types.Pointer:
+		if elem := reflectType(u.Elem(), seen); elem != nil {
+			return reflect.PointerTo(elem)
+		}
+
+	case *types.Slice:
+		if elem := reflectType(u.Elem(), seen); elem != nil {
+			return reflect.SliceOf(elem)
+		}
+
+	case *types.Array:
+		if elem := reflectType(u.Elem(), seen); elem != nil {
+			return reflect.ArrayOf(int(u.Len()), elem)
+		}
+
+	case *types.Map:
+		key, elem := reflectType(u.Key(), seen), reflectType(u.Elem(), seen)
+		if key != nil && elem != nil && key.Comparable() {
+			return reflect.MapOf(key, elem)
+		}
+
+	case *types.Struct:
+		return structType
+
+	case *types.Interface:
+		return anyType
+
+	case *types.Chan:
+		return reflect.TypeOf((chan struct{})(nil))
+
+	case *types.Signature:
+		return reflect.TypeOf(func() {})
+
+	}
+	return nil
+}
+
+func basicType(kind types.BasicKind) reflect.Type {
+	switch kind {
+	case types.Bool:
+		return reflect.TypeOf(false)
+	case types.Int:
+		return reflect.TypeOf(int(0))
+	case types.Int8:
+		return reflect.TypeOf(int8(0))
+	case types.Int16:
+		return reflect.TypeOf(int16(0))
+	case types.Int32:
+		return reflect.TypeOf(int32(0))
+	case types.Int64:
+		return reflect.TypeOf(int64(0))
+	case types.Uint:
+		return reflect.TypeOf(uint(0))
+	case types.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case types.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case types.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case types.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case types.Uintptr:
+		return reflect.TypeOf(uintptr(0))
+	case types.Float32:
+		return reflect.TypeOf(float32(0))
+	case types.Float64:
+		return reflect.TypeOf(float64(0))
+	case types.Complex64:
+		return reflect.TypeOf(complex64(0))
+	case types.Complex128:
+		return reflect.TypeOf(complex128(0))
+	case types.String:
+		return reflect.TypeOf("")
+	case types.UnsafePointer:
+		return reflect.TypeOf(unsafe.Pointer(nil))
+	default:
+		return nil
+	}
+}
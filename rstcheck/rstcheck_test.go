@@ -0,0 +1,27 @@
+package rstcheck
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func Test_Analyzer(t *testing.T) {
+	t.Run("Default Prefix", func(t *testing.T) {
+		analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+	})
+
+	t.Run("Custom Prefix And Rules", func(t *testing.T) {
+		setFlag(t, "prefix", "cfg-")
+		setFlag(t, "rules", "cfg-host")
+		analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+	})
+}
+
+func setFlag(t *testing.T, name, value string) {
+	old := Analyzer.Flags.Lookup(name).Value.String()
+	if err := Analyzer.Flags.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Analyzer.Flags.Set(name, old) })
+}
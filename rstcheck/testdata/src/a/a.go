@@ -0,0 +1,20 @@
+package a
+
+import "time"
+
+type Server struct {
+	Host    string        `json:"host" rst-maxlen:"253"`
+	Port    int           `json:"port" rst-min:"low"` // want `tag rst-min: invalid struct tags: strconv.Atoi: parsing "low": invalid syntax`
+	Timeout time.Duration `json:"timeout" rst-min:"1s" rst-max:"1m" rst-default:"30s"`
+	Retries *int          `json:"retries" rst-min:"5" rst-max:"3"`                    // want `tag rst-min: greater than rst-max 3: invalid struct tags`
+	Level   string        `json:"level" rst-choice:"info||debug" rst-default:"trace"` // want `tag rst-default: value trace is changed by rst-choice`
+	Tags    []string      `json:"tags" rst-max:"10"`                                  // want `tag rst-max: invalid struct tags`
+	Name    string        `json:"name" rst-maxln:"10"`                                // want `tag rst-maxln: unknown rule: invalid struct tags`
+	Mode    string        `json:"mode" rst-default-if:"@level=debug"`                 // want `tag rst-default-if: no default`
+	Backup  string        `json:"backup" rst-required-if:"@host"`
+	Nested  struct {
+		Ratio float64 `json:"ratio" rst-max:"one"` // want `tag rst-max: .*parsing "one"`
+	} `json:"nested"`
+
+	port int `rst-min:"low"`
+}
@@ -0,0 +1,7 @@
+package b
+
+type Server struct {
+	Port int    `json:"port" cfg-min:"low"`                 // want `tag cfg-min: invalid struct tags: strconv.Atoi: parsing "low": invalid syntax`
+	Host string `json:"host" cfg-host:"dns" cfg-hots:"dns"` // want `tag cfg-hots: unknown rule: invalid struct tags`
+	Name string `json:"name" rst-min:"low"`
+}